@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// accessReview asks the API server whether the current user is allowed to
+// access the resource described by attrs
+func (o *NsOptions) accessReview(attrs authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	clientset, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+	return o.reviewAccess(clientset, attrs)
+}
+
+// reviewAccess sends the access review with clientset, it is safe for
+// concurrent use
+func (o *NsOptions) reviewAccess(clientset kubernetes.Interface, attrs authorizationv1.ResourceAttributes) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+	}
+	var result *authorizationv1.SelfSubjectAccessReview
+	err := o.withRetry(func() (err error) {
+		result, err = clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(o.ctx, review, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review access: %w", err)
+	}
+	return &result.Status, nil
+}
+
+// preflightList reviews whether the user may list namespaces before the
+// list is requested, a denial is returned as Forbidden error. If the review
+// itself fails, the list is requested anyway.
+func (o *NsOptions) preflightList() error {
+	status, err := o.accessReview(authorizationv1.ResourceAttributes{Verb: "list", Resource: "namespaces"})
+	if err != nil || status.Allowed {
+		return nil
+	}
+	reason := "not allowed to list namespaces at the cluster scope"
+	if status.Reason != "" {
+		reason += ": " + status.Reason
+	}
+	return apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", errors.New(reason))
+}
+
+// missingPermission describes the RBAC rule required to perform verb on
+// namespaces and how to get it
+func missingPermission(verb, name string) string {
+	rule := fmt.Sprintf("apiGroups: [\"\"], resources: [\"namespaces\"], verbs: [\"%s\"]", verb)
+	if name != "" {
+		rule += fmt.Sprintf(", resourceNames: [\"%s\"]", name)
+	}
+	return fmt.Sprintf("the permission to %s namespaces is missing, ask your cluster administrator for a ClusterRoleBinding to a ClusterRole with the rule {%s}", verb, rule)
+}
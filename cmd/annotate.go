@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	annotateExample = `
+	# set the owner and contact annotations of the namespace foo
+	kubectl ns annotate foo owner=team-payments contact=payments@example.com
+
+	# remove the contact annotation of the namespace foo
+	kubectl ns annotate foo contact-
+
+	# list the annotations of the namespace foo
+	kubectl ns annotate foo --list`
+)
+
+// AnnotateOptions provides information required to change or list the
+// annotations of a namespace
+type AnnotateOptions struct {
+	ns   *NsOptions
+	name string
+
+	change    metadataChange
+	overwrite bool
+	list      bool
+}
+
+// NewAnnotateCmd provides a cobra command adding, removing and listing the
+// annotations of a namespace
+func NewAnnotateCmd(ns *NsOptions) *cobra.Command {
+	opt := &AnnotateOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "annotate namespace key=value... [key-...]",
+		Short:        "Add, remove or list the annotations of a namespace",
+		Example:      annotateExample,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.overwrite, "overwrite", false, "change the value of annotations which are already set")
+	cmd.Flags().BoolVar(&opt.list, "list", false, "list the annotations of the namespace instead of changing them")
+
+	return cmd
+}
+
+// Complete sets all information required for changing or listing the
+// annotations
+func (o *AnnotateOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+
+	var err error
+	o.change, err = parseMetadataChange(args[1:], "annotation", func(key, value string) []string {
+		return validation.IsQualifiedName(key)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if target, ok := o.ns.config.Aliases[o.name]; ok {
+		o.name = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *AnnotateOptions) Validate() error {
+	changes := len(o.change.set) + len(o.change.remove)
+	if o.list && changes > 0 {
+		return fmt.Errorf("--list accepts no annotations to set or remove")
+	}
+	if !o.list && changes == 0 {
+		return fmt.Errorf("at least one annotation to set or remove is required, use --list to show them")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run patches the annotations of the namespace, --dry-run prints the
+// patch. With --list the annotations are printed sorted by key.
+func (o *AnnotateOptions) Run() error {
+	if o.list {
+		return o.printAnnotations()
+	}
+
+	return o.ns.patchMetadata(o.name, "annotations", func(ns *v1.Namespace) (map[string]interface{}, error) {
+		return o.change.patch(ns.GetAnnotations(), "annotation", o.overwrite, o.ns.ErrOut)
+	})
+}
+
+func (o *AnnotateOptions) printAnnotations() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+	var ns *v1.Namespace
+	err = o.ns.withRetry(func() (err error) {
+		ns, err = clientset.CoreV1().Namespaces().Get(o.ns.ctx, o.name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	annotations := ns.GetAnnotations()
+	if len(annotations) == 0 {
+		fmt.Fprintf(o.ns.ErrOut, "namespace \"%s\" has no annotations\n", o.name)
+		return nil
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(o.ns.Out, "%s=%s\n", k, annotations[k])
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/user"
+	"path"
+	"text/tabwriter"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/audit"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditExample = `
+	# list all recorded namespace switches
+	kubectl ns audit
+
+	# list the switches to production namespaces of the last week
+	kubectl ns audit --namespace-pattern 'prod-*' --since 168h
+
+	# print the matching entries as JSON lines
+	kubectl ns audit --context-pattern 'prod*' -o json`
+)
+
+// AuditOptions provides information required to query the audit log
+type AuditOptions struct {
+	ns  *NsOptions
+	log *audit.Log
+
+	since            time.Duration
+	contextPattern   string
+	namespacePattern string
+	output           string
+}
+
+// NewAuditCmd provides a cobra command querying the audit log
+func NewAuditCmd(ns *NsOptions) *cobra.Command {
+	opt := &AuditOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "audit",
+		Short:        "Query the audit log of namespace switches",
+		Example:      auditExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&opt.since, "since", 0, "only show entries newer than the duration, e.g. 24h")
+	cmd.Flags().StringVar(&opt.contextPattern, "context-pattern", "", "only show switches in contexts matching the shell pattern")
+	cmd.Flags().StringVar(&opt.namespacePattern, "namespace-pattern", "", "only show switches to namespaces matching the shell pattern")
+	cmd.Flags().StringVarP(&opt.output, "output", "o", "", "output format, json prints the entries as JSON lines")
+
+	return cmd
+}
+
+// Complete sets all information required for reading the audit log
+func (o *AuditOptions) Complete(cmd *cobra.Command, args []string) error {
+	var err error
+	o.log, err = audit.NewDefaultLog()
+
+	return err
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *AuditOptions) Validate() error {
+	if o.since < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+	for _, pattern := range []string{o.contextPattern, o.namespacePattern} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern \"%s\": %w", pattern, err)
+		}
+	}
+	if o.output != "" && o.output != outputJSON {
+		return fmt.Errorf("unsupported output format \"%s\", use json", o.output)
+	}
+
+	return nil
+}
+
+// Run prints the matching entries of the audit log, oldest first
+func (o *AuditOptions) Run() error {
+	entries, err := o.log.List()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	matching := []audit.Entry{}
+	for _, e := range entries {
+		if o.matches(e) {
+			matching = append(matching, e)
+		}
+	}
+
+	if o.output == outputJSON {
+		enc := json.NewEncoder(o.ns.Out)
+		for _, e := range matching {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tCONTEXT\tSERVER\tFROM\tTO")
+	for _, e := range matching {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.User, e.Context, e.Server, e.FromNamespace, e.ToNamespace)
+	}
+	return w.Flush()
+}
+
+func (o *AuditOptions) matches(e audit.Entry) bool {
+	if o.since > 0 && e.Time.Before(time.Now().Add(-o.since)) {
+		return false
+	}
+	if ok, _ := path.Match(o.contextPattern, e.Context); o.contextPattern != "" && !ok {
+		return false
+	}
+	if ok, _ := path.Match(o.namespacePattern, e.ToNamespace); o.namespacePattern != "" && !ok {
+		return false
+	}
+	return true
+}
+
+// recordAudit appends the switch to the audit log, failures are reported
+// as warnings to w
+func (o *NsOptions) recordAudit(e switchEvent, w io.Writer) {
+	entry := audit.Entry{
+		Time:          time.Now(),
+		User:          localUser(),
+		Context:       e.ToContext,
+		FromNamespace: e.FromNamespace,
+		ToNamespace:   e.ToNamespace,
+	}
+	if e.FromContext != e.ToContext {
+		entry.FromContext = e.FromContext
+	}
+	if ctx, ok := o.rawConfig.Contexts[e.ToContext]; ok {
+		entry.Cluster = ctx.Cluster
+		if cluster, ok := o.rawConfig.Clusters[ctx.Cluster]; ok {
+			entry.Server = cluster.Server
+		}
+	}
+
+	log, err := audit.NewDefaultLog()
+	if err == nil {
+		err = log.Append(entry)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "warning: failed to write audit log: %v\n", err)
+	}
+}
+
+// localUser returns the name of the user running the plugin
+func localUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
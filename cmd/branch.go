@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// currentBranch returns the git branch checked out in the current directory
+func currentBranch() (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the git branch: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return "", fmt.Errorf("no git branch is checked out")
+	}
+	return branch, nil
+}
+
+// branchNamespace sets the user specified namespace to the namespace
+// derived from the current git branch, the namespace is created if the
+// matching rule says so
+func (o *NsOptions) branchNamespace() error {
+	branch, err := currentBranch()
+	if err != nil {
+		return err
+	}
+
+	namespace, rule, ok := o.config.BranchNamespace(branch)
+	if !ok {
+		return fmt.Errorf("no branch rule in the configuration matches the branch \"%s\"", branch)
+	}
+	if namespace == "" {
+		return fmt.Errorf("the branch rule \"%s\" results in an empty namespace for the branch \"%s\"", rule.Pattern, branch)
+	}
+
+	o.userSpecifiedNamespace = namespace
+	o.create = o.create || rule.Create
+	return nil
+}
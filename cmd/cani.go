@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	canIExample = `
+	# show what you may do in the current namespace
+	kubectl ns can-i
+
+	# show what you may do in the namespace foo before switching to it
+	kubectl ns can-i foo
+
+	# review other resources
+	kubectl ns can-i foo --resources pods,cronjobs.batch,certificates.cert-manager.io`
+)
+
+// canIVerbs are the verbs reviewed for every resource
+var canIVerbs = []string{"get", "list", "create", "delete"}
+
+// canIResources are the resources reviewed by default, resources of API
+// groups are qualified with the group
+var canIResources = []string{
+	"pods",
+	"deployments.apps",
+	"statefulsets.apps",
+	"jobs.batch",
+	"services",
+	"ingresses.networking.k8s.io",
+	"configmaps",
+	"secrets",
+	"persistentvolumeclaims",
+	"rolebindings.rbac.authorization.k8s.io",
+}
+
+// CanIOptions provides information required to review the permissions in a
+// namespace
+type CanIOptions struct {
+	ns   *NsOptions
+	name string
+
+	resources []string
+}
+
+// NewCanICmd provides a cobra command printing which verbs the user may
+// perform on common resources of a namespace
+func NewCanICmd(ns *NsOptions) *cobra.Command {
+	opt := &CanIOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "can-i [namespace]",
+		Short:        "Show what you are allowed to do in the current or a given namespace",
+		Example:      canIExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&opt.resources, "resources", canIResources, "resources to review, resources of API groups are qualified with the group like deployments.apps")
+
+	return cmd
+}
+
+// Complete sets all information required for reviewing the permissions, the
+// current namespace is used if none is given
+func (o *CanIOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	// the reviews are sent concurrently like the details of wide output
+	if o.ns.qps == 0 && o.ns.burst == 0 {
+		o.ns.qps, o.ns.burst = enrichQPS, enrichBurst
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+	o.name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *CanIOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+	if len(o.resources) == 0 {
+		return fmt.Errorf("--resources must not be empty")
+	}
+
+	return nil
+}
+
+// Run reviews all verbs on all resources concurrently and prints the
+// results as matrix
+func (o *CanIOptions) Run() error {
+	// the workers share one client and its rate limits
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+
+	allowed := make([][]bool, len(o.resources))
+	errs := make([]error, len(o.resources)*len(canIVerbs))
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, enrichWorkers)
+	for i, resource := range o.resources {
+		allowed[i] = make([]bool, len(canIVerbs))
+		gr := schema.ParseGroupResource(resource)
+		for j, verb := range canIVerbs {
+			wg.Add(1)
+			go func(i, j int, verb string) {
+				defer wg.Done()
+				workers <- struct{}{}
+				defer func() { <-workers }()
+
+				status, err := o.ns.reviewAccess(clientset, authorizationv1.ResourceAttributes{
+					Namespace: o.name,
+					Verb:      verb,
+					Group:     gr.Group,
+					Resource:  gr.Resource,
+				})
+				if err != nil {
+					errs[i*len(canIVerbs)+j] = err
+					return
+				}
+				allowed[i][j] = status.Allowed
+			}(i, j, verb)
+		}
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "RESOURCE\t%s\n", strings.ToUpper(strings.Join(canIVerbs, "\t")))
+	for i, resource := range o.resources {
+		cells := make([]string, len(canIVerbs))
+		for j := range canIVerbs {
+			cells[j] = "no"
+			if allowed[i][j] {
+				cells[j] = "yes"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", resource, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// capsuleGroup is the API group of the Capsule Tenant CRD
+	capsuleGroup = "capsule.clastix.io"
+	// labelCapsuleTenant is set by Capsule on the namespaces of a tenant
+	labelCapsuleTenant = "capsule.clastix.io/tenant"
+)
+
+// namespaceTenant returns the Capsule tenant owning the namespace, the
+// owner reference takes precedence over the tenant label
+func namespaceTenant(ns v1.Namespace) string {
+	for _, ref := range ns.GetOwnerReferences() {
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err == nil && gv.Group == capsuleGroup && ref.Kind == "Tenant" {
+			return ref.Name
+		}
+	}
+	return ns.GetLabels()[labelCapsuleTenant]
+}
+
+// tenantResource returns the Tenant resource of the preferred Capsule API
+// version, found is false if Capsule is not installed
+func (o *NsOptions) tenantResource() (gvr schema.GroupVersionResource, found bool, err error) {
+	clientset, err := o.client()
+	if err != nil {
+		return gvr, false, err
+	}
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		return gvr, false, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	for _, g := range groups.Groups {
+		if g.Name == capsuleGroup {
+			return schema.GroupVersionResource{Group: capsuleGroup, Version: g.PreferredVersion.Version, Resource: "tenants"}, true, nil
+		}
+	}
+	return gvr, false, nil
+}
+
+// tenantNamespaces returns the namespaces listed in the status of the
+// tenant. Tenant owners usually may not read the Tenant, in this case the
+// owner references of the namespaces are used on their own.
+func (o *NsOptions) tenantNamespaces(tenant string) (map[string]bool, error) {
+	namespaces := map[string]bool{}
+	if o.offline {
+		return namespaces, nil
+	}
+
+	gvr, found, err := o.tenantResource()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("--tenant requires Capsule, the API group %s is not available", capsuleGroup)
+	}
+
+	client, err := o.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	var obj *unstructured.Unstructured
+	err = o.withRetry(func() (err error) {
+		obj, err = client.Resource(gvr).Get(o.ctx, tenant, metav1.GetOptions{})
+		return err
+	})
+	switch {
+	case apierrors.IsForbidden(err):
+		return namespaces, nil
+	case apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("tenant \"%s\" does not exist", tenant)
+	case err != nil:
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	names, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "namespaces")
+	for _, name := range names {
+		namespaces[name] = true
+	}
+	return namespaces, nil
+}
+
+// filterTenant removes all namespaces which don't belong to the tenant
+func (o *NsOptions) filterTenant(namespaces []v1.Namespace) ([]v1.Namespace, error) {
+	members, err := o.tenantNamespaces(o.tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]v1.Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if members[ns.GetName()] || namespaceTenant(ns) == o.tenant {
+			result = append(result, ns)
+		}
+	}
+	return result, nil
+}
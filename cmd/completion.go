@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// completionCacheTTL is the minimum cache age used for completion in order
+// to keep it fast while typing
+const completionCacheTTL = 30 * time.Second
+
+var (
+	completionExample = `
+	# load completions for the current bash session
+	source <(kubectl-ns completion bash)
+
+	# load completions for every new zsh session
+	kubectl-ns completion zsh > "${fpath[1]}/_kubectl-ns"
+
+	# load completions for fish
+	kubectl-ns completion fish > ~/.config/fish/completions/kubectl-ns.fish`
+)
+
+// NewCompletionCmd provides a cobra command generating shell completion scripts
+func NewCompletionCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "completion bash|zsh|fish|powershell",
+		Short:                 "Generate shell completion scripts",
+		Example:               completionExample,
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.ExactValidArgs(1),
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			// the completion scripts are registered for the binary name
+			// instead of the name of the kubectl sub command
+			root := c.Root()
+			root.Use = "kubectl-ns"
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletion(streams.Out)
+			case "zsh":
+				return root.GenZshCompletion(streams.Out)
+			case "fish":
+				return root.GenFishCompletion(streams.Out, true)
+			case "powershell":
+				return root.GenPowerShellCompletion(streams.Out)
+			}
+			return fmt.Errorf("unsupported shell \"%s\"", args[0])
+		},
+	}
+	return cmd
+}
+
+// completeNamespaces returns the namespaces starting with toComplete, the
+// namespace list is cached for a short time
+func (o *NsOptions) completeNamespaces(args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names, err := o.cachedNamespaceNames(completionCacheTTL)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	completions := []string{}
+	for _, name := range names {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cachedNamespaceNames returns the namespace names of the current cluster,
+// the cache is used if it is not older than ttl
+func (o *NsOptions) cachedNamespaceNames(ttl time.Duration) ([]string, error) {
+	// warnings would end up in the completion results, they are only
+	// dropped while listing for the completion
+	defer func(errOut io.Writer) {
+		o.ErrOut = errOut
+	}(o.ErrOut)
+	o.ErrOut = ioutil.Discard
+
+	if err := o.loadConfig(); err != nil {
+		return nil, err
+	}
+	if err := o.checkContext(); err != nil {
+		return nil, err
+	}
+
+	if o.cacheTTL < ttl {
+		o.cacheTTL = ttl
+	}
+	if err := o.listNamespaces(); err != nil {
+		return nil, err
+	}
+	return namespaceNames(o.namespaces.Items), nil
+}
+
+// cacheKey identifies the cluster and user of the current context, the
+// --cluster, --user, --server and --token flags override them. Namespaces
+// listed with impersonation are cached for the impersonated user.
+func (o *NsOptions) cacheKey() string {
+	ctx := o.rawConfig.Contexts[o.contextName()]
+
+	clusterName := flagValue(o.configFlags.ClusterName, ctx.Cluster)
+	server := ""
+	if cluster, ok := o.rawConfig.Clusters[clusterName]; ok {
+		server = cluster.Server
+	}
+	server = flagValue(o.configFlags.APIServer, server)
+
+	user := flagValue(o.configFlags.AuthInfoName, ctx.AuthInfo)
+	if token := flagValue(o.configFlags.BearerToken, ""); token != "" {
+		user += "\x00" + token
+	}
+	if as, groups := o.impersonation(); as != "" {
+		user += "\x00" + as + "\x00" + strings.Join(groups, ",")
+	}
+	return cache.Key(server, user)
+}
+
+// flagValue returns the value of a config flag, fallback if it is not set
+func flagValue(flag *string, fallback string) string {
+	if flag == nil || *flag == "" {
+		return fallback
+	}
+	return *flag
+}
+
+// impersonation returns the user and groups of --as and --as-group
+func (o *NsOptions) impersonation() (string, []string) {
+	var groups []string
+	if o.configFlags.ImpersonateGroup != nil {
+		groups = *o.configFlags.ImpersonateGroup
+	}
+	return flagValue(o.configFlags.Impersonate, ""), groups
+}
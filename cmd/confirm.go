@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// confirm asks the user a yes/no question on the terminal, anything but
+// y or yes is considered a no
+func (o *NsOptions) confirm(question string) (bool, error) {
+	if !isTerminal(o.In) {
+		return false, fmt.Errorf("confirmation required but no terminal available, use --yes to skip it")
+	}
+
+	fmt.Fprintf(o.ErrOut, "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(o.In).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextsExample = `
+	# list all contexts with their cluster, user and namespace
+	kubectl ns contexts
+
+	# list only the context names
+	kubectl ns contexts -o name`
+)
+
+// ContextsOptions provides information required to list the contexts
+type ContextsOptions struct {
+	ns     *NsOptions
+	output string
+}
+
+// contextListingItem is the machine readable representation of a context
+type contextListingItem struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace"`
+	Current   bool   `json:"current"`
+}
+
+// NewContextsCmd provides a cobra command listing all contexts
+func NewContextsCmd(ns *NsOptions) *cobra.Command {
+	opt := &ContextsOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "contexts",
+		Short:        "List all contexts with their cluster, user and namespace",
+		Example:      contextsExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&opt.output, "output", "o", "", "output format, one of: json|yaml|name")
+
+	return cmd
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *ContextsOptions) Validate() error {
+	switch o.output {
+	case "", outputJSON, outputYAML, outputName:
+		return nil
+	}
+	return fmt.Errorf("unsupported output format \"%s\", use one of: json|yaml|name", o.output)
+}
+
+// Run prints the contexts sorted by name, the current context is marked
+func (o *ContextsOptions) Run() error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	items := []contextListingItem{}
+	for name, ctx := range o.ns.rawConfig.Contexts {
+		namespace := ctx.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+		items = append(items, contextListingItem{
+			Name:      name,
+			Cluster:   ctx.Cluster,
+			User:      ctx.AuthInfo,
+			Namespace: namespace,
+			Current:   name == o.ns.rawConfig.CurrentContext,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	switch o.output {
+	case outputJSON, outputYAML:
+		o.ns.output = o.output
+		return o.ns.printObject(items)
+	case outputName:
+		for _, item := range items {
+			fmt.Fprintln(o.ns.Out, item.Name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENT\tNAME\tCLUSTER\tUSER\tNAMESPACE")
+	for _, item := range items {
+		current := ""
+		if item.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", current, item.Name, item.Cluster, item.User, item.Namespace)
+	}
+	return w.Flush()
+}
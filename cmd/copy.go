@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	copyExample = `
+	# copy the pull secret regcred and the config map app-config from staging to preview-123
+	kubectl ns copy --from staging --to preview-123 --secrets regcred --configmaps app-config
+
+	# replace the config maps app-config and feature-flags of preview-123 with the ones of the current namespace
+	kubectl ns copy --to preview-123 --configmaps app-config,feature-flags --overwrite`
+)
+
+// copyObject is an object of the source namespace prepared to be created in
+// the target namespace
+type copyObject struct {
+	kind     string
+	resource schema.GroupVersionResource
+	obj      *unstructured.Unstructured
+	exists   bool
+}
+
+// CopyOptions provides information required to copy config maps and secrets
+// between namespaces
+type CopyOptions struct {
+	ns *NsOptions
+
+	from       string
+	to         string
+	secrets    []string
+	configMaps []string
+	overwrite  bool
+}
+
+// NewCopyCmd provides a cobra command copying config maps and secrets from
+// one namespace to another
+func NewCopyCmd(ns *NsOptions) *cobra.Command {
+	opt := &CopyOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "copy --to namespace [--from namespace] [--secrets names] [--configmaps names]",
+		Short:        "Copy config maps and secrets to another namespace",
+		Example:      copyExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opt.from, "from", "", "namespace to copy from, defaults to the current namespace")
+	cmd.Flags().StringVar(&opt.to, "to", "", "namespace to copy to")
+	cmd.Flags().StringSliceVar(&opt.secrets, "secrets", nil, "names of the secrets to copy")
+	cmd.Flags().StringSliceVar(&opt.configMaps, "configmaps", nil, "names of the config maps to copy")
+	cmd.Flags().BoolVar(&opt.overwrite, "overwrite", false, "replace objects which already exist in the target namespace")
+
+	return cmd
+}
+
+// Complete sets all information required for copying the objects
+func (o *CopyOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+
+	if o.from == "" {
+		o.from = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+	}
+	if target, ok := o.ns.config.Aliases[o.from]; ok {
+		o.from = target
+	}
+	if target, ok := o.ns.config.Aliases[o.to]; ok {
+		o.to = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *CopyOptions) Validate() error {
+	if o.to == "" {
+		return fmt.Errorf("--to must not be empty")
+	}
+	if o.from == o.to {
+		return fmt.Errorf("--from and --to must be different namespaces")
+	}
+	if len(o.secrets) == 0 && len(o.configMaps) == 0 {
+		return fmt.Errorf("at least one of --secrets and --configmaps is required")
+	}
+
+	return nil
+}
+
+// Run reads all objects of the source namespace and checks the target
+// namespace before anything is created, existing objects are only replaced
+// with --overwrite
+func (o *CopyOptions) Run() error {
+	if err := o.ns.validateContextNamespace(o.ns.contextName(), o.to); err != nil {
+		return err
+	}
+
+	dyn, err := o.ns.dynamicClient()
+	if err != nil {
+		return err
+	}
+	objects, err := o.sourceObjects(dyn)
+	if err != nil {
+		return err
+	}
+
+	for i := range objects {
+		c := &objects[i]
+		err := o.ns.withRetry(func() error {
+			_, err := dyn.Resource(c.resource).Namespace(o.to).Get(o.ns.ctx, c.obj.GetName(), metav1.GetOptions{})
+			return err
+		})
+		switch {
+		case err == nil:
+			if !o.overwrite {
+				return fmt.Errorf("%s \"%s\" already exists in namespace \"%s\", use --overwrite to replace it", c.kind, c.obj.GetName(), o.to)
+			}
+			c.exists = true
+		case !apierrors.IsNotFound(err):
+			return fmt.Errorf("failed to get %s \"%s\": %w", c.kind, c.obj.GetName(), err)
+		}
+	}
+
+	for _, c := range objects {
+		verb := "copied to"
+		if c.exists {
+			verb = "replaced in"
+		}
+		if o.ns.dryRun {
+			fmt.Fprintf(o.ns.Out, "%s \"%s\" would be %s namespace \"%s\"\n", c.kind, c.obj.GetName(), verb, o.to)
+			continue
+		}
+
+		client := dyn.Resource(c.resource).Namespace(o.to)
+		err := o.ns.withRetry(func() error {
+			if !c.exists {
+				_, err := client.Create(o.ns.ctx, c.obj, metav1.CreateOptions{})
+				return err
+			}
+			current, err := client.Get(o.ns.ctx, c.obj.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			c.obj.SetResourceVersion(current.GetResourceVersion())
+			_, err = client.Update(o.ns.ctx, c.obj, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to copy %s \"%s\": %w", c.kind, c.obj.GetName(), err)
+		}
+		fmt.Fprintf(o.ns.Out, "%s \"%s\" %s namespace \"%s\"\n", c.kind, c.obj.GetName(), verb, o.to)
+	}
+	return nil
+}
+
+// sourceObjects returns the selected secrets and config maps of the source
+// namespace without the fields set by the API server or kubectl and without
+// finalizers. Tokens of service accounts are refused as they are only valid
+// for their namespace.
+func (o *CopyOptions) sourceObjects(dyn dynamic.Interface) ([]copyObject, error) {
+	selections := []struct {
+		kind     string
+		resource schema.GroupVersionResource
+		names    []string
+	}{
+		{"secret", v1.SchemeGroupVersion.WithResource("secrets"), o.secrets},
+		{"configmap", v1.SchemeGroupVersion.WithResource("configmaps"), o.configMaps},
+	}
+
+	objects := []copyObject{}
+	for _, s := range selections {
+		for _, name := range s.names {
+			var obj *unstructured.Unstructured
+			err := o.ns.withRetry(func() (err error) {
+				obj, err = dyn.Resource(s.resource).Namespace(o.from).Get(o.ns.ctx, name, metav1.GetOptions{})
+				return err
+			})
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%s \"%s\" not found in namespace \"%s\"", s.kind, name, o.from)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s \"%s\": %w", s.kind, name, err)
+			}
+			if t, _, _ := unstructured.NestedString(obj.Object, "type"); t == string(v1.SecretTypeServiceAccountToken) {
+				return nil, fmt.Errorf("secret \"%s\" is a service account token which can't be copied", name)
+			}
+
+			// like an export, the copy is created from a clean manifest
+			copied := &unstructured.Unstructured{Object: exportableObject(*obj)}
+			copied.SetNamespace(o.to)
+			objects = append(objects, copyObject{kind: s.kind, resource: s.resource, obj: copied})
+		}
+	}
+	return objects, nil
+}
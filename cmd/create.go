@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// createTimeout is the default maximum time to wait for a created
+	// namespace to become ready
+	createTimeout = time.Minute
+	pollInterval  = 500 * time.Millisecond
+	// defaultServiceAccount is created in every namespace by the service
+	// account controller
+	defaultServiceAccount = "default"
+)
+
+var (
+	createExample = `
+	# create the namespace foo with labels and annotations
+	kubectl ns create foo --labels team=payments,env=dev --annotations owner=team-payments
+
+	# create the namespace foo with a resource quota and a limit range and switch to it
+	kubectl ns create foo --quota-template quota.yaml --limits-template limits.yaml --switch
+
+	# bootstrap the namespace foo with the template team-default of the configuration
+	kubectl ns create foo --template team-default`
+)
+
+// CreateOptions provides information required to create a namespace
+type CreateOptions struct {
+	ns   *NsOptions
+	name string
+
+	labels         map[string]string
+	annotations    map[string]string
+	quotaTemplate  string
+	limitsTemplate string
+	template       string
+	timeout        time.Duration
+	serviceAccount bool
+	switchTo       bool
+}
+
+// NewCreateCmd provides a cobra command creating a namespace
+func NewCreateCmd(ns *NsOptions) *cobra.Command {
+	opt := &CreateOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "create namespace",
+		Short:        "Create a namespace with labels, annotations, a resource quota and a limit range",
+		Example:      createExample,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringToStringVar(&opt.labels, "labels", nil, "labels of the namespace (e.g. --labels team=payments,env=dev)")
+	cmd.Flags().StringToStringVar(&opt.annotations, "annotations", nil, "annotations of the namespace (e.g. --annotations owner=team-payments)")
+	cmd.Flags().StringVar(&opt.quotaTemplate, "quota-template", "", "YAML file of a resource quota created in the namespace")
+	cmd.Flags().StringVar(&opt.limitsTemplate, "limits-template", "", "YAML file of a limit range created in the namespace")
+	cmd.Flags().StringVar(&opt.template, "template", "", "bootstrap the namespace with the labels, annotations and manifests of the template of the configuration")
+	cmd.Flags().DurationVar(&opt.timeout, "timeout", createTimeout, "maximum time to wait for the namespace to become ready")
+	cmd.Flags().BoolVar(&opt.serviceAccount, "wait-service-account", false, "also wait for the default service account and its token secrets")
+	cmd.Flags().BoolVar(&opt.switchTo, "switch", false, "switch to the namespace after it was created")
+
+	return cmd
+}
+
+// Complete sets all information required for creating the namespace
+func (o *CreateOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	o.ns.userSpecifiedNamespace = o.name
+	o.ns.labels = o.labels
+	o.ns.annotations = o.annotations
+	o.ns.quotaTemplate = o.quotaTemplate
+	o.ns.limitsTemplate = o.limitsTemplate
+	o.ns.template = o.template
+	o.ns.createTimeout = o.timeout
+	o.ns.waitServiceAccount = o.serviceAccount
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *CreateOptions) Validate() error {
+	if errs := validation.IsDNS1123Label(o.name); len(errs) > 0 {
+		return fmt.Errorf("invalid namespace name \"%s\": %s", o.name, strings.Join(errs, "; "))
+	}
+	if o.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if err := o.ns.loadCreateTemplates(); err != nil {
+		return err
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run creates the namespace and its resource quota and limit range, an
+// existing namespace is an error
+func (o *CreateOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+	_, err = clientset.CoreV1().Namespaces().Get(o.ns.ctx, o.name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		return fmt.Errorf("namespace \"%s\" already exists", o.name)
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	if err := o.ns.createNamespace(); err != nil {
+		return err
+	}
+	if !o.switchTo || o.ns.dryRun {
+		return nil
+	}
+	return o.ns.changeCurrentNs(o.name)
+}
+
+// createNamespace creates the user specified namespace if it does not exist
+// yet and waits until it is ready
+func (o *NsOptions) createNamespace() error {
+	clientset, err := o.client()
+	if err != nil {
+		return err
+	}
+	namespaces := clientset.CoreV1().Namespaces()
+
+	_, err = namespaces.Get(o.ctx, o.userSpecifiedNamespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+	// unknown kinds are reported before anything is created
+	resources, err := o.manifestResources(clientset)
+	if err != nil {
+		return err
+	}
+	if o.dryRun {
+		fmt.Fprintf(o.Out, "namespace \"%s\" would be created\n", o.userSpecifiedNamespace)
+		if o.quota != nil {
+			fmt.Fprintf(o.Out, "resource quota \"%s\" would be created\n", o.quota.GetName())
+		}
+		if o.limitRange != nil {
+			fmt.Fprintf(o.Out, "limit range \"%s\" would be created\n", o.limitRange.GetName())
+		}
+		for _, obj := range o.manifests {
+			fmt.Fprintf(o.Out, "%s \"%s\" would be created\n", strings.ToLower(obj.GetKind()), obj.GetName())
+		}
+		return nil
+	}
+
+	ns := &v1.Namespace{}
+	ns.SetName(o.userSpecifiedNamespace)
+	ns.SetLabels(o.labels)
+	ns.SetAnnotations(o.annotations)
+	if _, err := namespaces.Create(o.ctx, ns, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+	fmt.Fprintf(o.Out, "namespace \"%s\" created\n", o.userSpecifiedNamespace)
+
+	if err := o.waitReady(o.userSpecifiedNamespace, o.createTimeout, o.waitServiceAccount); err != nil {
+		return err
+	}
+	return o.applyCreateTemplates(clientset, o.userSpecifiedNamespace, resources)
+}
+
+// loadCreateTemplates reads the resource quota and the limit range of
+// --quota-template and --limits-template, they are created without name
+// with the name default. The labels, annotations and manifests of the
+// configured template of --template are added.
+func (o *NsOptions) loadCreateTemplates() error {
+	if o.template != "" {
+		t, ok := o.config.Templates[o.template]
+		if !ok {
+			return fmt.Errorf("template \"%s\" is not configured", o.template)
+		}
+		o.labels = mergeMissing(o.labels, t.Labels)
+		o.annotations = mergeMissing(o.annotations, t.Annotations)
+		o.manifests = make([]unstructured.Unstructured, 0, len(t.Manifests))
+		for _, m := range t.Manifests {
+			obj := unstructured.Unstructured{Object: runtime.DeepCopyJSON(m)}
+			obj.SetNamespace("")
+			o.manifests = append(o.manifests, obj)
+		}
+	}
+	if o.quotaTemplate != "" {
+		o.quota = &v1.ResourceQuota{}
+		if err := loadTemplate(o.quotaTemplate, "ResourceQuota", o.quota); err != nil {
+			return err
+		}
+	}
+	if o.limitsTemplate != "" {
+		o.limitRange = &v1.LimitRange{}
+		if err := loadTemplate(o.limitsTemplate, "LimitRange", o.limitRange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateObject is an object read from a template file
+type templateObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// loadTemplate parses the YAML or JSON manifest at path into obj, the kind
+// is checked if the manifest sets it. The server managed fields and the
+// namespace are cleared.
+func loadTemplate(path, kind string, obj templateObject) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(data, obj); err != nil {
+		return fmt.Errorf("failed to parse template %s: %w", path, err)
+	}
+	if k := obj.GetObjectKind().GroupVersionKind().Kind; k != "" && k != kind {
+		return fmt.Errorf("template %s contains a %s instead of a %s", path, k, kind)
+	}
+
+	if obj.GetName() == "" {
+		obj.SetName("default")
+	}
+	obj.SetNamespace("")
+	clearServerFields(obj)
+	return nil
+}
+
+// clearServerFields removes the metadata set by the API server, the object
+// can be created again
+func clearServerFields(obj metav1.Object) {
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetSelfLink("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetDeletionGracePeriodSeconds(nil)
+	obj.SetOwnerReferences(nil)
+	obj.SetManagedFields(nil)
+}
+
+// applyCreateTemplates creates the resource quota and the limit range of
+// the templates and the manifests of the configured template in the
+// created namespace
+func (o *NsOptions) applyCreateTemplates(clientset kubernetes.Interface, namespace string, resources []schema.GroupVersionResource) error {
+	if o.quota != nil {
+		if _, err := clientset.CoreV1().ResourceQuotas(namespace).Create(o.ctx, o.quota, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create resource quota: %w", err)
+		}
+		fmt.Fprintf(o.Out, "resource quota \"%s\" created\n", o.quota.GetName())
+	}
+	if o.limitRange != nil {
+		if _, err := clientset.CoreV1().LimitRanges(namespace).Create(o.ctx, o.limitRange, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create limit range: %w", err)
+		}
+		fmt.Fprintf(o.Out, "limit range \"%s\" created\n", o.limitRange.GetName())
+	}
+	if len(o.manifests) == 0 {
+		return nil
+	}
+
+	dyn, err := o.dynamicClient()
+	if err != nil {
+		return err
+	}
+	for i, obj := range o.manifests {
+		kind := strings.ToLower(obj.GetKind())
+		obj.SetNamespace(namespace)
+		if _, err := dyn.Resource(resources[i]).Namespace(namespace).Create(o.ctx, &obj, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create %s \"%s\": %w", kind, obj.GetName(), err)
+		}
+		fmt.Fprintf(o.Out, "%s \"%s\" created\n", kind, obj.GetName())
+	}
+	return nil
+}
+
+// manifestResources returns the resources of the manifests of the template
+// in the same order, manifests of unknown or cluster scoped kinds are an
+// error
+func (o *NsOptions) manifestResources(clientset kubernetes.Interface) ([]schema.GroupVersionResource, error) {
+	if len(o.manifests) == 0 {
+		return nil, nil
+	}
+	groups, err := restmapper.GetAPIGroupResources(clientset.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API resources: %w", err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groups)
+
+	resources := make([]schema.GroupVersionResource, 0, len(o.manifests))
+	for _, obj := range o.manifests {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("template \"%s\": %w", o.template, err)
+		}
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			return nil, fmt.Errorf("template \"%s\": %s \"%s\" is not namespaced", o.template, obj.GetKind(), obj.GetName())
+		}
+		resources = append(resources, mapping.Resource)
+	}
+	return resources, nil
+}
+
+// mergeMissing adds the entries of defaults which are not set in m
+func mergeMissing(m, defaults map[string]string) map[string]string {
+	if len(defaults) == 0 {
+		return m
+	}
+	merged := map[string]string{}
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range m {
+		merged[k] = v
+	}
+	return merged
+}
+
+// waitReady waits until the namespace exists and is active. With
+// serviceAccount it also waits for the default service account and the
+// token secrets it references, which are created by controllers shortly
+// after the namespace.
+func (o *NsOptions) waitReady(name string, timeout time.Duration, serviceAccount bool) error {
+	clientset, err := o.client()
+	if err != nil {
+		return err
+	}
+
+	pending := "it does not exist"
+	err = wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		var err error
+		pending, err = o.pendingReadiness(clientset, name, serviceAccount)
+		return pending == "", err
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("namespace \"%s\" is not ready after %s, %s", name, timeout, pending)
+	}
+	if err != nil {
+		return fmt.Errorf("namespace \"%s\" is not ready: %w", name, err)
+	}
+	return nil
+}
+
+// pendingReadiness returns why the namespace is not ready yet, an empty
+// string if it is. Terminating namespaces never become ready.
+func (o *NsOptions) pendingReadiness(clientset kubernetes.Interface, name string, serviceAccount bool) (string, error) {
+	ns, err := clientset.CoreV1().Namespaces().Get(o.ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return "it does not exist", nil
+	case err != nil:
+		return "", err
+	case ns.Status.Phase == v1.NamespaceTerminating:
+		return "", fmt.Errorf("it is terminating")
+	case ns.Status.Phase != v1.NamespaceActive:
+		return fmt.Sprintf("its phase is %s", ns.Status.Phase), nil
+	case !serviceAccount:
+		return "", nil
+	}
+
+	sa, err := clientset.CoreV1().ServiceAccounts(name).Get(o.ctx, defaultServiceAccount, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("the service account \"%s\" does not exist", defaultServiceAccount), nil
+	case err != nil:
+		return "", err
+	}
+	for _, ref := range sa.Secrets {
+		_, err := clientset.CoreV1().Secrets(name).Get(o.ctx, ref.Name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			return fmt.Sprintf("the secret \"%s\" of the service account \"%s\" does not exist", ref.Name, defaultServiceAccount), nil
+		case err != nil:
+			return "", err
+		}
+	}
+	return "", nil
+}
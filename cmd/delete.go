@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// progressInterval is the minimum time between two listings of the
+// remaining resources while waiting for a deleted namespace
+const progressInterval = 2 * time.Second
+
+var (
+	deleteExample = `
+	# delete the namespace foo after confirming the summary of its resources
+	kubectl ns delete foo
+
+	# delete the namespace foo without confirmation and wait until it is gone
+	kubectl ns delete foo --yes --wait`
+)
+
+// DeleteOptions provides information required to delete a namespace
+type DeleteOptions struct {
+	ns *NsOptions
+
+	name    string
+	yes     bool
+	wait    bool
+	timeout time.Duration
+}
+
+// NewDeleteCmd provides a cobra command deleting a namespace
+func NewDeleteCmd(ns *NsOptions) *cobra.Command {
+	opt := &DeleteOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "delete namespace",
+		Short:        "Delete a namespace",
+		Example:      deleteExample,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&opt.yes, "yes", "y", false, "delete without confirmation")
+	cmd.Flags().BoolVar(&opt.wait, "wait", false, "wait until the namespace is completely removed, the remaining resources are reported while waiting")
+	cmd.Flags().DurationVar(&opt.timeout, "timeout", 5*time.Minute, "maximum time to wait with --wait")
+
+	return cmd
+}
+
+// Complete sets all information required for deleting the namespace
+func (o *DeleteOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+
+	return o.ns.loadConfig()
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *DeleteOptions) Validate() error {
+	if o.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run deletes the namespace after the user confirmed the summary of the
+// resources which will be destroyed, --dry-run only prints the summary
+func (o *DeleteOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+	namespaces := clientset.CoreV1().Namespaces()
+
+	if _, err := namespaces.Get(o.ns.ctx, o.name, metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	if o.ns.dryRun {
+		if err := o.printSummary(); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.ns.Out, "namespace \"%s\" would be deleted\n", o.name)
+		return nil
+	}
+
+	if !o.yes {
+		if err := o.printSummary(); err != nil {
+			return err
+		}
+		ok, err := o.ns.confirm(fmt.Sprintf("delete namespace \"%s\"?", o.name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	if err := namespaces.Delete(o.ns.ctx, o.name, metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete namespace: %w", err)
+	}
+	fmt.Fprintf(o.ns.Out, "namespace \"%s\" deleted\n", o.name)
+
+	if o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace == o.name {
+		if err := o.ns.changeCurrentNs("default"); err != nil {
+			return err
+		}
+	}
+
+	if !o.wait {
+		return nil
+	}
+
+	var progress string
+	var reported time.Time
+	err = wait.PollImmediate(pollInterval, o.timeout, func() (bool, error) {
+		_, err := namespaces.Get(o.ns.ctx, o.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil || time.Since(reported) < progressInterval {
+			return false, err
+		}
+		reported = time.Now()
+		if current := o.progress(); current != "" && current != progress {
+			progress = current
+			fmt.Fprintln(o.ns.ErrOut, progress)
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("namespace \"%s\" was not removed after %s, run kubectl ns stuck %s to see what blocks it", o.name, o.timeout, o.name)
+	}
+	if err != nil {
+		return fmt.Errorf("namespace \"%s\" was not removed: %w", o.name, err)
+	}
+	fmt.Fprintf(o.ns.Out, "namespace \"%s\" removed\n", o.name)
+
+	return nil
+}
+
+// progress summarizes the resources remaining in the terminating
+// namespace, an empty string is returned if they can't be listed
+func (o *DeleteOptions) progress() string {
+	inventory, err := o.ns.namespaceInventory(o.name)
+	if err != nil {
+		return ""
+	}
+	if len(inventory) == 0 {
+		return fmt.Sprintf("namespace \"%s\" is terminating, no resources remaining", o.name)
+	}
+
+	total := 0
+	counts := make([]string, 0, len(inventory))
+	for _, item := range inventory {
+		total += len(item.Objects)
+		counts = append(counts, fmt.Sprintf("%s %d", item.Name(), len(item.Objects)))
+	}
+	return fmt.Sprintf("namespace \"%s\" is terminating, %d resources remaining: %s", o.name, total, strings.Join(counts, ", "))
+}
+
+func (o *DeleteOptions) printSummary() error {
+	inventory, err := o.ns.namespaceInventory(o.name)
+	if err != nil {
+		return fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	if len(inventory) == 0 {
+		fmt.Fprintf(o.ns.ErrOut, "namespace \"%s\" contains no resources\n", o.name)
+		return nil
+	}
+
+	fmt.Fprintf(o.ns.ErrOut, "the following resources in namespace \"%s\" will be destroyed:\n", o.name)
+	w := tabwriter.NewWriter(o.ns.ErrOut, 0, 8, 2, ' ', 0)
+	for _, item := range inventory {
+		fmt.Fprintf(w, "  %s\t%d\n", item.Name(), len(item.Objects))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// describeEvents is the number of recent events shown by describe
+const describeEvents = 10
+
+var (
+	describeExample = `
+	# describe the current namespace
+	kubectl ns describe
+
+	# describe the namespace foo
+	kubectl ns describe foo`
+)
+
+// DescribeOptions provides information required to describe a namespace
+type DescribeOptions struct {
+	ns   *NsOptions
+	name string
+}
+
+// NewDescribeCmd provides a cobra command describing a namespace
+func NewDescribeCmd(ns *NsOptions) *cobra.Command {
+	opt := &DescribeOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "describe [namespace]",
+		Short:        "Describe the current or a given namespace",
+		Example:      describeExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	return cmd
+}
+
+// Complete sets all information required for describing the namespace, the
+// current namespace is used if none is given
+func (o *DescribeOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+	o.name = o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace
+	if o.name == "" {
+		o.name = "default"
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *DescribeOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+
+	return nil
+}
+
+// Run prints the details of the namespace
+func (o *DescribeOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+	core := clientset.CoreV1()
+
+	ns, err := core.Namespaces().Get(o.ns.ctx, o.name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+	quotas, err := core.ResourceQuotas(o.name).List(o.ns.ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get resource quotas: %w", err)
+	}
+	limits, err := core.LimitRanges(o.name).List(o.ns.ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get limit ranges: %w", err)
+	}
+	events, err := core.Events(o.name).List(o.ns.ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", ns.GetName())
+	fmt.Fprintf(w, "Status:\t%s\n", ns.Status.Phase)
+	fmt.Fprintf(w, "Age:\t%s\n", age(ns.GetCreationTimestamp().Time))
+	printMap(w, "Labels", ns.GetLabels())
+	printMap(w, "Annotations", ns.GetAnnotations())
+
+	fmt.Fprintln(w)
+	printQuotas(w, quotas.Items)
+	fmt.Fprintln(w)
+	printLimitRanges(w, limits.Items)
+	fmt.Fprintln(w)
+	printEvents(w, events.Items, describeEvents)
+
+	return w.Flush()
+}
+
+// printMap prints labels or annotations sorted by key, one per line
+func printMap(w io.Writer, title string, m map[string]string) {
+	if len(m) == 0 {
+		fmt.Fprintf(w, "%s:\t<none>\n", title)
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		prefix := ""
+		if i == 0 {
+			prefix = title + ":"
+		}
+		fmt.Fprintf(w, "%s\t%s=%s\n", prefix, k, m[k])
+	}
+}
+
+func printQuotas(w io.Writer, quotas []v1.ResourceQuota) {
+	if len(quotas) == 0 {
+		fmt.Fprintln(w, "Resource Quotas:\t<none>")
+		return
+	}
+
+	fmt.Fprintln(w, "Resource Quotas:")
+	for _, q := range quotas {
+		fmt.Fprintf(w, "  %s\n", q.GetName())
+		fmt.Fprintln(w, "  Resource\tUsed\tHard")
+		for _, name := range sortedResourceNames(q.Status.Hard) {
+			used := q.Status.Used[name]
+			hard := q.Status.Hard[name]
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", name, used.String(), hard.String())
+		}
+	}
+}
+
+func printLimitRanges(w io.Writer, limits []v1.LimitRange) {
+	if len(limits) == 0 {
+		fmt.Fprintln(w, "Limit Ranges:\t<none>")
+		return
+	}
+
+	fmt.Fprintln(w, "Limit Ranges:")
+	for _, l := range limits {
+		fmt.Fprintf(w, "  %s\n", l.GetName())
+		fmt.Fprintln(w, "  Type\tResource\tMin\tMax\tDefault Request\tDefault Limit")
+		for _, item := range l.Spec.Limits {
+			for _, r := range limitResourceNames(item) {
+				fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\n", item.Type, r,
+					quantity(item.Min, r), quantity(item.Max, r), quantity(item.DefaultRequest, r), quantity(item.Default, r))
+			}
+		}
+	}
+}
+
+// limitResourceNames returns the sorted names of all resources constrained
+// by a limit range item
+func limitResourceNames(item v1.LimitRangeItem) []v1.ResourceName {
+	all := v1.ResourceList{}
+	for _, list := range []v1.ResourceList{item.Min, item.Max, item.DefaultRequest, item.Default} {
+		for name, q := range list {
+			all[name] = q
+		}
+	}
+	return sortedResourceNames(all)
+}
+
+func printEvents(w io.Writer, events []v1.Event, max int) {
+	if len(events) == 0 {
+		fmt.Fprintln(w, "Events:\t<none>")
+		return
+	}
+
+	sortEvents(events)
+	if len(events) > max {
+		events = events[len(events)-max:]
+	}
+
+	fmt.Fprintln(w, "Events:")
+	fmt.Fprintln(w, "  Last Seen\tType\tReason\tObject\tMessage")
+	for _, e := range events {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s/%s\t%s\n", age(eventTime(e).Time), e.Type, e.Reason,
+			strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, strings.TrimSpace(e.Message))
+	}
+}
+
+// sortEvents sorts events by the time they were seen last, oldest first
+func sortEvents(events []v1.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Time.Before(eventTime(events[j]).Time)
+	})
+}
+
+// eventTime returns the most accurate time an event was observed last
+func eventTime(e v1.Event) metav1.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp
+	case e.Series != nil && !e.Series.LastObservedTime.IsZero():
+		return metav1.Time{Time: e.Series.LastObservedTime.Time}
+	case !e.EventTime.IsZero():
+		return metav1.Time{Time: e.EventTime.Time}
+	}
+	return e.FirstTimestamp
+}
+
+func sortedResourceNames(list v1.ResourceList) []v1.ResourceName {
+	names := make([]v1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func quantity(list v1.ResourceList, name v1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return "-"
+	}
+	return q.String()
+}
@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts the command in a new process group, so it doesn't receive
+// the interrupts of the console
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	diffExample = `
+	# list the objects which only exist in one of the namespaces staging and production
+	kubectl ns diff staging production
+
+	# also compare the objects which exist in both namespaces
+	kubectl ns diff staging production --specs`
+)
+
+// generatedFields are set by the API server or controllers and differ
+// between namespaces even if the objects were created from one manifest
+var generatedFields = map[string][][]string{
+	"Namespace":      {{"spec", "finalizers"}},
+	"Service":        {{"spec", "clusterIP"}, {"spec", "clusterIPs"}},
+	"ServiceAccount": {{"secrets"}},
+}
+
+// generatedAnnotations are set by kubectl or controllers
+var generatedAnnotations = []string{
+	v1.LastAppliedConfigAnnotation,
+	"deployment.kubernetes.io/revision",
+}
+
+// objectDifference is an object which only exists in one namespace or
+// whose sanitized content differs
+type objectDifference struct {
+	marker string
+	object string
+	reason string
+}
+
+// DiffOptions provides information required to compare two namespaces
+type DiffOptions struct {
+	ns *NsOptions
+
+	first  string
+	second string
+	specs  bool
+}
+
+// NewDiffCmd provides a cobra command comparing the objects of two
+// namespaces
+func NewDiffCmd(ns *NsOptions) *cobra.Command {
+	opt := &DiffOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "diff namespace namespace",
+		Short:        "Compare the objects of two namespaces",
+		Example:      diffExample,
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.specs, "specs", false, "also compare the objects which exist in both namespaces, fields set by the cluster are ignored")
+
+	return cmd
+}
+
+// Complete sets all information required for comparing the namespaces
+func (o *DiffOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.first, o.second = args[0], args[1]
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if target, ok := o.ns.config.Aliases[o.first]; ok {
+		o.first = target
+	}
+	if target, ok := o.ns.config.Aliases[o.second]; ok {
+		o.second = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *DiffOptions) Validate() error {
+	if o.first == o.second {
+		return fmt.Errorf("the namespaces to compare must be different")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run prints the objects which only exist in one namespace, with --specs
+// also the objects which differ. Objects owned by other objects and service
+// account tokens are generated by the cluster and skipped. The exit code is
+// 1 if the namespaces differ.
+func (o *DiffOptions) Run() error {
+	for _, name := range []string{o.first, o.second} {
+		if err := o.ns.validateContextNamespace(o.ns.contextName(), name); err != nil {
+			return err
+		}
+	}
+
+	first, err := o.objects(o.first)
+	if err != nil {
+		return err
+	}
+	second, err := o.objects(o.second)
+	if err != nil {
+		return err
+	}
+
+	differences := o.compare(first, second)
+	if len(differences) == 0 {
+		fmt.Fprintf(o.ns.Out, "namespaces \"%s\" and \"%s\" contain the same objects\n", o.first, o.second)
+		return nil
+	}
+	printDifferences(o.ns.Out, differences)
+	return &ExitError{Code: 1}
+}
+
+// objects returns the objects of namespace which are compared, keyed by
+// resource/name
+func (o *DiffOptions) objects(namespace string) (map[string]unstructured.Unstructured, error) {
+	inventory, err := o.ns.namespaceInventory(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the objects of namespace \"%s\": %w", namespace, err)
+	}
+
+	objects := map[string]unstructured.Unstructured{}
+	for _, item := range inventory {
+		for _, obj := range item.Objects {
+			if len(obj.GetOwnerReferences()) > 0 {
+				continue
+			}
+			if t, _, _ := unstructured.NestedString(obj.Object, "type"); item.Kind == "Secret" && t == string(v1.SecretTypeServiceAccountToken) {
+				continue
+			}
+			objects[item.Name()+"/"+obj.GetName()] = obj
+		}
+	}
+	return objects, nil
+}
+
+// compare returns the differences of the objects sorted by resource/name
+func (o *DiffOptions) compare(first, second map[string]unstructured.Unstructured) []objectDifference {
+	keys := []string{}
+	for key := range first {
+		keys = append(keys, key)
+	}
+	for key := range second {
+		if _, ok := first[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	differences := []objectDifference{}
+	for _, key := range keys {
+		a, inFirst := first[key]
+		b, inSecond := second[key]
+		switch {
+		case !inSecond:
+			differences = append(differences, objectDifference{marker: "-", object: key, reason: fmt.Sprintf("only in \"%s\"", o.first)})
+		case !inFirst:
+			differences = append(differences, objectDifference{marker: "+", object: key, reason: fmt.Sprintf("only in \"%s\"", o.second)})
+		case o.specs:
+			if fields := differentFields(sanitizedObject(a), sanitizedObject(b)); len(fields) > 0 {
+				differences = append(differences, objectDifference{marker: "~", object: key, reason: strings.Join(fields, ", ") + " differ"})
+			}
+		}
+	}
+	return differences
+}
+
+// sanitizedObject returns the content of obj without the fields which are
+// specific to the namespace or set by the cluster, only the labels and
+// annotations remain of the metadata
+func sanitizedObject(obj unstructured.Unstructured) map[string]interface{} {
+	content := obj.DeepCopy().Object
+	delete(content, "status")
+	for _, path := range generatedFields[obj.GetKind()] {
+		unstructured.RemoveNestedField(content, path...)
+	}
+
+	metadata := map[string]interface{}{}
+	if labels := obj.GetLabels(); len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	annotations := obj.GetAnnotations()
+	for _, key := range generatedAnnotations {
+		delete(annotations, key)
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	content["metadata"] = metadata
+	return content
+}
+
+// differentFields returns the sorted top-level fields of the sanitized
+// objects which differ, labels and annotations are reported on their own
+func differentFields(a, b map[string]interface{}) []string {
+	fields := []string{}
+	for _, key := range []string{"labels", "annotations"} {
+		if !reflect.DeepEqual(a["metadata"].(map[string]interface{})[key], b["metadata"].(map[string]interface{})[key]) {
+			fields = append(fields, "metadata."+key)
+		}
+	}
+
+	keys := map[string]bool{}
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+	delete(keys, "metadata")
+	for key := range keys {
+		if !reflect.DeepEqual(a[key], b[key]) {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// printDifferences prints a line per difference, objects only in the first
+// namespace are red, objects only in the second green and differing objects
+// yellow
+func printDifferences(out io.Writer, differences []objectDifference) {
+	colors := map[string]*color.Color{
+		"-": color.New(color.FgRed),
+		"+": color.New(color.FgGreen),
+		"~": color.New(color.FgYellow),
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for _, d := range differences {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", colors[d.marker].Sprint(d.marker), d.object, d.reason)
+	}
+	w.Flush()
+}
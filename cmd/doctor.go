@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	"github.com/spf13/cobra"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+var (
+	doctorExample = `
+	# check the setup of the current context
+	kubectl ns doctor
+
+	# check another context
+	kubectl ns doctor --context prod`
+)
+
+// doctorCheck is a single diagnostic, run returns a description of the
+// result. A failed check returns the remedy besides the error. Checks
+// marked as required skip the remaining checks if they fail.
+type doctorCheck struct {
+	name     string
+	required bool
+	run      func() (result, remedy string, err error)
+}
+
+// DoctorOptions provides information required to diagnose the setup
+type DoctorOptions struct {
+	ns *NsOptions
+
+	loadErr error
+}
+
+// NewDoctorCmd provides a cobra command diagnosing the kubeconfig, the
+// connection to the API server and the local files of the plugin
+func NewDoctorCmd(ns *NsOptions) *cobra.Command {
+	opt := &DoctorOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "doctor",
+		Short:        "Diagnose the kubeconfig, the API server access and the cache",
+		Example:      doctorExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// Complete loads the configuration, a broken kubeconfig is reported by the
+// first check instead of failing the command
+func (o *DoctorOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.loadErr = o.ns.loadConfig()
+	return nil
+}
+
+// Run runs all checks and prints their results, the exit code is 1 if a
+// check failed
+func (o *DoctorOptions) Run() error {
+	checks := []doctorCheck{
+		{name: "kubeconfig", required: true, run: o.checkKubeconfig},
+		{name: "kubeconfig writable", run: o.checkWritable},
+		{name: "current context", required: true, run: o.checkContext},
+		{name: "namespace cache", run: o.checkCache},
+		{name: "API server", required: true, run: o.checkServer},
+		{name: "list namespaces", run: o.checkAccess("list", "")},
+		{name: "get namespace", run: o.checkAccess("get", "current")},
+	}
+
+	failed := 0
+	skip := ""
+	for _, check := range checks {
+		if skip != "" {
+			fmt.Fprintf(o.ns.Out, "skip  %s: requires %s\n", check.name, skip)
+			continue
+		}
+		result, remedy, err := check.run()
+		if err == nil {
+			fmt.Fprintf(o.ns.Out, "ok    %s: %s\n", check.name, result)
+			continue
+		}
+		failed++
+		fmt.Fprintf(o.ns.Out, "FAIL  %s: %v\n", check.name, err)
+		if remedy != "" {
+			fmt.Fprintf(o.ns.Out, "      fix: %s\n", remedy)
+		}
+		if check.required {
+			skip = "the " + check.name
+		}
+	}
+
+	if failed > 0 {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+func (o *DoctorOptions) checkKubeconfig() (string, string, error) {
+	if o.loadErr != nil {
+		return "", "fix the syntax of the file, kubectl ns restore rolls back to a backup", o.loadErr
+	}
+	if o.ns.inCluster {
+		return "not found, using the service account of the pod", "", nil
+	}
+
+	// the current context is checked on its own
+	c := o.ns.rawConfig.DeepCopy()
+	c.CurrentContext = ""
+	if err := clientcmd.Validate(*c); err != nil {
+		if clientcmd.IsEmptyConfig(err) {
+			return "", "create a kubeconfig, e.g. with the login command of your cloud provider, and set KUBECONFIG", fmt.Errorf("no contexts found in %s", strings.Join(o.ns.kubeconfigPaths(), string(os.PathListSeparator)))
+		}
+		return "", "fix the listed entries with kubectl config set-cluster, set-credentials or set-context", err
+	}
+	return fmt.Sprintf("contexts: %d, clusters: %d, users: %d", len(c.Contexts), len(c.Clusters), len(c.AuthInfos)), "", nil
+}
+
+func (o *DoctorOptions) checkWritable() (string, string, error) {
+	if o.ns.inCluster {
+		return "not used in a pod", "", nil
+	}
+	paths := o.ns.kubeconfigPaths()
+	if o.ns.writeTo != "" {
+		paths = []string{o.ns.writeTo}
+	}
+	checked := []string{}
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) && len(checked) > 0 {
+			continue
+		}
+		if err := safefile.Writable(path); err != nil {
+			return "", fmt.Sprintf("allow writing to the directory of %s or use --write-to with a writable file", path), err
+		}
+		checked = append(checked, path)
+	}
+	return strings.Join(checked, ", "), "", nil
+}
+
+func (o *DoctorOptions) checkContext() (string, string, error) {
+	name := o.ns.contextName()
+	if name == "" {
+		return "", "select a context with kubectl config use-context", fmt.Errorf("no current context set")
+	}
+	if err := o.ns.checkContext(); err != nil {
+		return "", "select an existing context with kubectl ns contexts or kubectl config use-context", err
+	}
+	ctx := o.ns.rawConfig.Contexts[name]
+	return fmt.Sprintf("%s (namespace %s)", name, namespaceOrDefault(ctx.Namespace)), "", nil
+}
+
+func (o *DoctorOptions) checkServer() (string, string, error) {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return "", "check the cluster and user of the context", err
+	}
+	var info fmt.Stringer
+	err = o.ns.withRetry(func() error {
+		v, err := clientset.Discovery().ServerVersion()
+		info = v
+		return err
+	})
+	switch {
+	case apierrors.IsUnauthorized(err):
+		return "", "the credentials were rejected, log in again or renew the token of the user", err
+	case err != nil:
+		return "", "check the server URL, VPN and proxy settings, or increase --request-timeout", ExplainError(err)
+	}
+	restConfig, err := o.ns.restConfig()
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s at %s", info, restConfig.Host), "", nil
+}
+
+// checkAccess returns a check reviewing whether the user may perform verb
+// on namespaces, target "current" reviews the namespace of the context
+func (o *DoctorOptions) checkAccess(verb, target string) func() (string, string, error) {
+	return func() (string, string, error) {
+		attrs := authorizationv1.ResourceAttributes{Verb: verb, Resource: "namespaces"}
+		what := verb + " namespaces"
+		if target == "current" {
+			attrs.Name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+			what = fmt.Sprintf("%s namespace %s", verb, attrs.Name)
+		}
+		status, err := o.ns.accessReview(attrs)
+		if err != nil {
+			return "", "", err
+		}
+		if !status.Allowed {
+			remedy := fmt.Sprintf("ask your cluster administrator for a ClusterRole allowing %s on namespaces", verb)
+			if verb == "list" {
+				remedy += ", without it only known namespaces can be switched to"
+			}
+			return "", remedy, fmt.Errorf("not allowed to %s", what)
+		}
+		return "allowed", "", nil
+	}
+}
+
+func (o *DoctorOptions) checkCache() (string, string, error) {
+	dir, err := cache.Dir()
+	if err != nil {
+		return "", "set KUBECTL_NS_CACHE_DIR to a writable directory", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "set KUBECTL_NS_CACHE_DIR to a writable directory", err
+	}
+	c := &cache.Cache{Dir: dir, TTL: o.ns.cacheTTL}
+	e, err := c.Load(o.ns.cacheKey())
+	if err != nil {
+		return "", fmt.Sprintf("remove the broken file in %s or run kubectl ns --refresh", dir), fmt.Errorf("failed to read the cached namespaces: %w", err)
+	}
+	if e == nil {
+		return fmt.Sprintf("%s, no namespaces cached for the context", dir), "", nil
+	}
+	state := "fresh"
+	if c.Stale(e) {
+		state = "stale"
+	}
+	return fmt.Sprintf("%s, %d namespaces cached at %s (%s)", dir, len(e.Namespaces), e.Time.Local().Format("2006-01-02 15:04:05"), state), "", nil
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// enrichWorkers is the number of namespaces whose details are fetched
+	// concurrently
+	enrichWorkers = 10
+	// enrichQPS and enrichBurst replace the client default rate limits if
+	// details of many namespaces are fetched and no limits are configured
+	enrichQPS   = 50
+	enrichBurst = 100
+)
+
+// namespaceDetails are the extras of a namespace shown in wide output
+type namespaceDetails struct {
+	pods        string
+	quota       string
+	deployments string
+	services    string
+}
+
+// forEachNamespace calls fn for every namespace, at most enrichWorkers
+// calls run concurrently. fn receives the index of the namespace and must
+// only write to data belonging to this index.
+func forEachNamespace(namespaces []v1.Namespace, fn func(i int, ns v1.Namespace)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < enrichWorkers && w < len(namespaces); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i, namespaces[i])
+			}
+		}()
+	}
+
+	for i := range namespaces {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// namespaceDetails fetches the details of all namespaces concurrently, a
+// warning reports namespaces whose details are not available
+func (o *NsOptions) namespaceDetails(namespaces []v1.Namespace) []namespaceDetails {
+	details := make([]namespaceDetails, len(namespaces))
+	errs := make([]error, len(namespaces))
+
+	clientset, err := o.client()
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to get namespace details: %v\n", err)
+		for i := range details {
+			details[i] = unknownDetails()
+		}
+		return details
+	}
+
+	forEachNamespace(namespaces, func(i int, ns v1.Namespace) {
+		details[i] = unknownDetails()
+
+		pods, err := countObjects(func(opts metav1.ListOptions) (runtime.Object, error) {
+			return clientset.CoreV1().Pods(ns.GetName()).List(o.ctx, opts)
+		})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		details[i].pods = strconv.Itoa(pods)
+
+		quotas, err := clientset.CoreV1().ResourceQuotas(ns.GetName()).List(o.ctx, metav1.ListOptions{})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		details[i].quota = quotaUsage(quotas.Items)
+
+		if !o.counts {
+			return
+		}
+		deployments, err := countObjects(func(opts metav1.ListOptions) (runtime.Object, error) {
+			return clientset.AppsV1().Deployments(ns.GetName()).List(o.ctx, opts)
+		})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		details[i].deployments = strconv.Itoa(deployments)
+
+		services, err := countObjects(func(opts metav1.ListOptions) (runtime.Object, error) {
+			return clientset.CoreV1().Services(ns.GetName()).List(o.ctx, opts)
+		})
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		details[i].services = strconv.Itoa(services)
+	})
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			if failed == 0 {
+				fmt.Fprintf(o.ErrOut, "warning: failed to get namespace details: %v\n", err)
+			}
+			failed++
+		}
+	}
+	if failed > 1 {
+		fmt.Fprintf(o.ErrOut, "warning: details of %d namespaces are unknown\n", failed)
+	}
+
+	return details
+}
+
+// unknownDetails are shown for namespaces whose details could not be
+// fetched
+func unknownDetails() namespaceDetails {
+	return namespaceDetails{pods: "<unknown>", quota: "<unknown>", deployments: "<unknown>", services: "<unknown>"}
+}
+
+// countObjects returns the number of objects returned by list. Only a
+// single object is requested, the remaining item count of the API server
+// provides the total. Servers without remaining item count are asked for
+// the complete list.
+func countObjects(list func(opts metav1.ListOptions) (runtime.Object, error)) (int, error) {
+	result, err := list(metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	accessor, err := meta.ListAccessor(result)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case accessor.GetContinue() == "":
+		return meta.LenList(result), nil
+	case accessor.GetRemainingItemCount() != nil:
+		return meta.LenList(result) + int(*accessor.GetRemainingItemCount()), nil
+	}
+
+	if result, err = list(metav1.ListOptions{}); err != nil {
+		return 0, err
+	}
+	return meta.LenList(result), nil
+}
+
+// quotaUsage returns the highest usage of all hard limits in percent
+func quotaUsage(quotas []v1.ResourceQuota) string {
+	if len(quotas) == 0 {
+		return "<none>"
+	}
+
+	max := 0.0
+	for _, q := range quotas {
+		for name, hard := range q.Status.Hard {
+			used := q.Status.Used[name]
+			if hard.IsZero() {
+				continue
+			}
+			if usage := float64(used.MilliValue()) / float64(hard.MilliValue()); usage > max {
+				max = usage
+			}
+		}
+	}
+	return fmt.Sprintf("%.0f%%", max*100)
+}
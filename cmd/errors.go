@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ExplainError adds a hint to errors caused by an API server which did not
+// respond in time, other errors are returned unchanged
+func ExplainError(err error) error {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr) && netErr.Timeout(),
+		apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return fmt.Errorf("%w\nthe API server did not respond in time, check the connection or use --request-timeout to wait longer", err)
+	}
+	return err
+}
+
+// errAborted is returned if the user declined a confirmation, scripts must
+// not take the unchanged state for success
+var errAborted = errors.New("aborted")
+
+// ExitError is returned if a child process exited with a non-zero code,
+// the code is passed on as exit code of the plugin
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
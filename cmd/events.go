@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+var (
+	eventsExample = `
+	# list the recent events of the current namespace
+	kubectl ns events
+
+	# list the warnings of the namespace foo and watch for new ones
+	kubectl ns events foo --warnings --watch`
+)
+
+// EventsOptions provides information required to list the events of a
+// namespace
+type EventsOptions struct {
+	ns   *NsOptions
+	name string
+
+	watch    bool
+	warnings bool
+}
+
+// NewEventsCmd provides a cobra command listing the recent events of a
+// namespace
+func NewEventsCmd(ns *NsOptions) *cobra.Command {
+	opt := &EventsOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "events [namespace]",
+		Short:        "List the recent events of the current or a given namespace",
+		Example:      eventsExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&opt.watch, "watch", "w", false, "after listing the events, watch for new ones")
+	cmd.Flags().BoolVar(&opt.warnings, "warnings", false, "only list events of type Warning")
+
+	return cmd
+}
+
+// Complete sets all information required for listing the events, the
+// current namespace is used if none is given
+func (o *EventsOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+	o.name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *EventsOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+
+	return nil
+}
+
+// Run lists the events of the namespace sorted by the time they were seen
+// last, the most recent event is printed last. With --watch new and
+// updated events are printed until the command is interrupted.
+func (o *EventsOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+
+	opts := metav1.ListOptions{}
+	if o.warnings {
+		opts.FieldSelector = "type=" + v1.EventTypeWarning
+	}
+	var events *v1.EventList
+	err = o.ns.withRetry(func() (err error) {
+		events, err = clientset.CoreV1().Events(o.name).List(o.ns.ctx, opts)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get events: %w", err)
+	}
+	if len(events.Items) == 0 && !o.watch {
+		fmt.Fprintf(o.ns.ErrOut, "no events found in namespace \"%s\"\n", o.name)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "LAST SEEN\tTYPE\tREASON\tOBJECT\tMESSAGE")
+	sortEvents(events.Items)
+	for _, e := range events.Items {
+		printEvent(w, e)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if !o.watch {
+		return nil
+	}
+
+	resourceVersion := events.GetResourceVersion()
+	for {
+		opts.ResourceVersion = resourceVersion
+		watcher, err := clientset.CoreV1().Events(o.name).Watch(o.ns.ctx, opts)
+		if err == nil {
+			resourceVersion, err = o.watchEvents(w, watcher, resourceVersion)
+		} else {
+			err = fmt.Errorf("failed to watch events: %w", err)
+		}
+		// the watch ends with the context on interrupt
+		if o.ns.ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resourceVersion != "" {
+			continue
+		}
+
+		// a watch without resource version would replay all events, so
+		// the watch continues from the resource version of a new list
+		err = o.ns.withRetry(func() (err error) {
+			events, err = clientset.CoreV1().Events(o.name).List(o.ns.ctx, metav1.ListOptions{FieldSelector: opts.FieldSelector, Limit: 1})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+		resourceVersion = events.GetResourceVersion()
+	}
+}
+
+// watchEvents prints all added and updated events of watcher until the
+// result channel is closed and returns the resource version to resume from,
+// it is empty if the resource version expired
+func (o *EventsOptions) watchEvents(w *tabwriter.Writer, watcher watch.Interface, resourceVersion string) (string, error) {
+	defer watcher.Stop()
+
+	for event := range watcher.ResultChan() {
+		if event.Type == watch.Error {
+			status := apierrors.FromObject(event.Object)
+			if apierrors.IsResourceExpired(status) || apierrors.IsGone(status) {
+				// start watching from the most recent state
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to watch events: %w", status)
+		}
+
+		e, ok := event.Object.(*v1.Event)
+		if !ok {
+			continue
+		}
+		resourceVersion = e.GetResourceVersion()
+		if event.Type == watch.Deleted {
+			continue
+		}
+
+		printEvent(w, *e)
+		if err := w.Flush(); err != nil {
+			return "", err
+		}
+	}
+
+	return resourceVersion, nil
+}
+
+// printEvent prints an event as row of the events table
+func printEvent(w io.Writer, e v1.Event) {
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s/%s\t%s\n", age(eventTime(e).Time), e.Type, e.Reason,
+		strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name, strings.TrimSpace(e.Message))
+}
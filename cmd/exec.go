@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+var (
+	execExample = `
+	# list the pods of the namespace foo without switching to it
+	kubectl ns exec foo -- kubectl get pods
+
+	# run a script against the namespace bar of the context prod
+	kubectl ns exec bar --context prod -- ./smoke-test.sh`
+)
+
+// childRunning is set while a child process runs in the foreground
+var childRunning int32
+
+// ChildRunning reports whether a child process runs in the foreground.
+// Interrupts from the terminal reach the child as well, so they are left to
+// the child instead of aborting the plugin.
+func ChildRunning() bool {
+	return atomic.LoadInt32(&childRunning) == 1
+}
+
+// ExecOptions provides information required to run a command against a
+// namespace
+type ExecOptions struct {
+	ns *NsOptions
+
+	namespace string
+	command   []string
+	force     bool
+}
+
+// NewExecCmd provides a cobra command running a command with a temporary
+// KUBECONFIG using another namespace
+func NewExecCmd(ns *NsOptions) *cobra.Command {
+	opt := &ExecOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "exec namespace -- command [args...]",
+		Short:        "Run a command against a namespace without switching",
+		Example:      execExample,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&opt.force, "force", "f", false, "run the command without checking whether the namespace exists")
+
+	return cmd
+}
+
+// Complete sets all information required for running the command
+func (o *ExecOptions) Complete(cmd *cobra.Command, args []string) error {
+	if cmd.ArgsLenAtDash() != 1 {
+		return fmt.Errorf("separate the command with --, e.g. kubectl ns exec foo -- kubectl get pods")
+	}
+	o.namespace = args[0]
+	o.command = args[1:]
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if target, ok := o.ns.config.Aliases[o.namespace]; ok {
+		o.namespace = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *ExecOptions) Validate() error {
+	return o.ns.checkContext()
+}
+
+// Run executes the command with a temporary KUBECONFIG which only contains
+// the context with its namespace set, the exit code of the command is
+// passed on
+func (o *ExecOptions) Run() error {
+	if !o.force {
+		if err := o.ns.validateContextNamespace(o.ns.contextName(), o.namespace); err != nil {
+			return err
+		}
+	}
+
+	path, err := o.ns.writeTempKubeconfig(o.namespace)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	return o.ns.runChild(o.command, path)
+}
+
+// writeTempKubeconfig writes a KUBECONFIG containing only the selected
+// context with its namespace set to namespace. Credentials referenced by
+// files are embedded, so the file is only readable by the user.
+func (o *NsOptions) writeTempKubeconfig(namespace string) (string, error) {
+	config := o.rawConfig.DeepCopy()
+	name := o.contextName()
+	config.CurrentContext = name
+	config.Contexts[name].Namespace = namespace
+	o.applyOverrides(config, name)
+	if err := clientcmdapi.MinifyConfig(config); err != nil {
+		return "", err
+	}
+	if err := clientcmdapi.FlattenConfig(config); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile("", "kubectl-ns-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+
+	if err := clientcmd.WriteToFile(*config, f.Name()); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write temporary KUBECONFIG: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// applyOverrides merges the overrides of the kubeconfig flags into the
+// named context of config the way the client does, so the command sees the
+// user, cluster and credentials the namespace was validated with
+func (o *NsOptions) applyOverrides(config *clientcmdapi.Config, name string) {
+	overrides := o.configOverrides(name)
+	ctx := config.Contexts[name]
+	if overrides.Context.Cluster != "" {
+		ctx.Cluster = overrides.Context.Cluster
+	}
+	if overrides.Context.AuthInfo != "" {
+		ctx.AuthInfo = overrides.Context.AuthInfo
+	}
+
+	if cluster, ok := config.Clusters[ctx.Cluster]; ok {
+		override := overrides.ClusterInfo
+		if override.Server != "" {
+			cluster.Server = override.Server
+		}
+		if override.Server != "" || override.TLSServerName != "" {
+			cluster.TLSServerName = override.TLSServerName
+		}
+		if override.InsecureSkipTLSVerify || override.CertificateAuthority != "" {
+			cluster.InsecureSkipTLSVerify = override.InsecureSkipTLSVerify
+			cluster.CertificateAuthority = override.CertificateAuthority
+			cluster.CertificateAuthorityData = nil
+		}
+	}
+
+	authInfo, ok := config.AuthInfos[ctx.AuthInfo]
+	if !ok {
+		return
+	}
+	override := overrides.AuthInfo
+	if override.ClientCertificate != "" {
+		authInfo.ClientCertificate = override.ClientCertificate
+	}
+	if override.ClientKey != "" {
+		authInfo.ClientKey = override.ClientKey
+	}
+	if override.Token != "" {
+		authInfo.Token = override.Token
+	}
+	if override.Username != "" {
+		authInfo.Username = override.Username
+	}
+	if override.Password != "" {
+		authInfo.Password = override.Password
+	}
+	if override.Impersonate != "" {
+		authInfo.Impersonate = override.Impersonate
+	}
+	if len(override.ImpersonateGroups) > 0 {
+		authInfo.ImpersonateGroups = override.ImpersonateGroups
+	}
+	if o.usesLogin(name) && authInfo.AuthProvider != nil {
+		authInfo.AuthProvider.Config[oidcIDToken] = o.loginToken
+	}
+}
+
+// runChild runs the command with the streams of the plugin, KUBECONFIG is
+// set to kubeconfig unless it is empty. SIGTERM is forwarded to the child, a
+// non-zero exit code is returned as ExitError.
+func (o *NsOptions) runChild(command []string, kubeconfig string) error {
+	child := exec.Command(command[0], command[1:]...)
+	child.Stdin = o.In
+	child.Stdout = o.Out
+	child.Stderr = o.ErrOut
+	if kubeconfig != "" {
+		child.Env = append(environWithout("KUBECONFIG"), "KUBECONFIG="+kubeconfig)
+	}
+
+	atomic.StoreInt32(&childRunning, 1)
+	defer atomic.StoreInt32(&childRunning, 0)
+
+	if err := child.Start(); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := child.Wait()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			code = 1
+		}
+		return &ExitError{Code: code}
+	}
+	return err
+}
+
+// environWithout returns the environment without the variable key
+func environWithout(key string) []string {
+	env := []string{}
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(e, key+"=") {
+			env = append(env, e)
+		}
+	}
+	return env
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	exportExample = `
+	# export the objects of the current namespace to the directory of its name
+	kubectl ns export
+
+	# export the objects of the namespace foo to ./backup before a risky change
+	kubectl ns export foo --output-dir ./backup`
+)
+
+// ExportOptions provides information required to export the objects of a
+// namespace
+type ExportOptions struct {
+	ns   *NsOptions
+	name string
+
+	outputDir string
+}
+
+// NewExportCmd provides a cobra command writing the objects of a namespace
+// to YAML files
+func NewExportCmd(ns *NsOptions) *cobra.Command {
+	opt := &ExportOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "export [namespace]",
+		Short:        "Export the objects of a namespace to YAML files",
+		Example:      exportExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opt.outputDir, "output-dir", "", "directory to write the files to, defaults to the name of the namespace")
+
+	return cmd
+}
+
+// Complete sets all information required for exporting the namespace
+func (o *ExportOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+	} else {
+		if err := o.ns.checkContext(); err != nil {
+			return err
+		}
+		o.name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+	}
+
+	if o.outputDir == "" {
+		o.outputDir = o.name
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided,
+// files of a previous export are not mixed with the new ones
+func (o *ExportOptions) Validate() error {
+	entries, err := ioutil.ReadDir(o.outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("output directory \"%s\" is not empty", o.outputDir)
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run writes the namespace to namespace.yaml and every object to
+// <resource>/<name>.yaml below the output directory. Objects owned by other
+// objects and service account tokens are recreated by the cluster and
+// skipped. --dry-run prints the files instead.
+func (o *ExportOptions) Run() error {
+	dyn, err := o.ns.dynamicClient()
+	if err != nil {
+		return err
+	}
+	var ns *unstructured.Unstructured
+	err = o.ns.withRetry(func() (err error) {
+		ns, err = dyn.Resource(v1.SchemeGroupVersion.WithResource("namespaces")).Get(o.ns.ctx, o.name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	inventory, err := o.ns.namespaceInventory(o.name)
+	if err != nil {
+		return fmt.Errorf("failed to list the objects of namespace \"%s\": %w", o.name, err)
+	}
+
+	files := map[string]unstructured.Unstructured{
+		filepath.Join(o.outputDir, "namespace.yaml"): *ns,
+	}
+	for _, item := range inventory {
+		for _, obj := range item.Objects {
+			if len(obj.GetOwnerReferences()) > 0 {
+				continue
+			}
+			if t, _, _ := unstructured.NestedString(obj.Object, "type"); item.Kind == "Secret" && t == string(v1.SecretTypeServiceAccountToken) {
+				continue
+			}
+			files[filepath.Join(o.outputDir, item.Name(), obj.GetName()+".yaml")] = obj
+		}
+	}
+
+	paths := []string{}
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		obj := files[path]
+		if o.ns.dryRun {
+			fmt.Fprintf(o.ns.Out, "%s would be written\n", path)
+			continue
+		}
+		if err := writeExport(path, exportableObject(obj)); err != nil {
+			return err
+		}
+	}
+	if !o.ns.dryRun {
+		fmt.Fprintf(o.ns.Out, "%d objects of namespace \"%s\" exported to %s\n", len(files), o.name, o.outputDir)
+	}
+	return nil
+}
+
+// exportableObject returns obj without its namespace, its status and the
+// fields set by the API server or controllers, it can be applied to
+// another namespace
+func exportableObject(obj unstructured.Unstructured) map[string]interface{} {
+	obj = *obj.DeepCopy()
+	clearServerFields(&obj)
+	obj.SetNamespace("")
+	obj.SetFinalizers(nil)
+	delete(obj.Object, "status")
+	for _, path := range generatedFields[obj.GetKind()] {
+		unstructured.RemoveNestedField(obj.Object, path...)
+	}
+
+	annotations := obj.GetAnnotations()
+	for _, key := range generatedAnnotations {
+		delete(annotations, key)
+	}
+	obj.SetAnnotations(annotations)
+	return obj.Object
+}
+
+// writeExport writes content as YAML to path, the files may contain
+// secrets and are only readable by the user
+func writeExport(path string, content map[string]interface{}) error {
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/postfinance/kubectl-ns/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	favExample = `
+	# bookmark the namespace foo for all contexts
+	kubectl ns fav add foo
+
+	# bookmark the namespace bar only for the context prod
+	kubectl ns fav add bar --context prod
+
+	# list and remove bookmarks
+	kubectl ns fav list
+	kubectl ns fav rm foo`
+)
+
+// FavOptions provides information required to manage favorite namespaces
+type FavOptions struct {
+	ns      *NsOptions
+	context string
+}
+
+// NewFavCmd provides a cobra command managing favorite namespaces
+func NewFavCmd(ns *NsOptions) *cobra.Command {
+	opt := &FavOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:     "fav",
+		Short:   "Manage favorite namespaces, shown first in listings",
+		Example: favExample,
+	}
+	cmd.PersistentFlags().StringVar(&opt.context, "context", "", "restrict the favorite to a context, by default it applies to all contexts")
+
+	cmd.AddCommand(&cobra.Command{
+		Use:          "add namespace",
+		Short:        "Add a favorite namespace",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return opt.update(args[0], (*state.State).AddFavorite, "already a favorite", "added to the favorites")
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:          "rm namespace",
+		Aliases:      []string{"remove"},
+		Short:        "Remove a favorite namespace",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return opt.update(args[0], (*state.State).RemoveFavorite, "not a favorite", "removed from the favorites")
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:          "list",
+		Aliases:      []string{"ls"},
+		Short:        "List favorite namespaces",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			return opt.list()
+		},
+	})
+
+	return cmd
+}
+
+// update applies fn to the favorite namespace and saves the state if fn
+// reports a change, --dry-run prints the change instead
+func (o *FavOptions) update(namespace string, fn func(*state.State, state.Favorite) bool, unchanged, changed string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	f := state.Favorite{
+		Namespace: namespace,
+		Context:   o.context,
+	}
+	return o.ns.updateState(func(s *state.State) (bool, error) {
+		if !fn(s, f) {
+			return false, fmt.Errorf("namespace \"%s\" is %s", namespace, unchanged)
+		}
+		if o.ns.dryRun {
+			fmt.Fprintf(o.ns.Out, "namespace \"%s\" would be %s\n", namespace, changed)
+			return false, nil
+		}
+		return true, nil
+	})
+}
+
+func (o *FavOptions) list() error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	s, err := o.ns.loadState()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMESPACE\tCONTEXT")
+	for _, f := range s.Favorites {
+		if o.context != "" && f.Context != o.context {
+			continue
+		}
+		context := f.Context
+		if context == "" {
+			context = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\n", f.Namespace, context)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	"github.com/postfinance/kubectl-ns/pkg/history"
+	v1 "k8s.io/api/core/v1"
+)
+
+// prepareNamespaces removes hidden namespaces and with --terminating all
+// namespaces which are not terminating from the list and sorts it,
+// favorites are moved to the top
+func (o *NsOptions) prepareNamespaces(namespaces []v1.Namespace) []v1.Namespace {
+	result := make([]v1.Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if o.isHidden(ns.GetName()) || o.terminating && ns.Status.Phase != v1.NamespaceTerminating {
+			continue
+		}
+		result = append(result, ns)
+	}
+
+	less := o.lessFunc()
+	sort.SliceStable(result, func(i, j int) bool {
+		if o.sortOrder == config.SortDescending {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+
+	s, err := o.loadState()
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to load favorites: %v\n", err)
+		return result
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return s.IsFavorite(o.contextName(), result[i].GetName()) &&
+			!s.IsFavorite(o.contextName(), result[j].GetName())
+	})
+
+	return result
+}
+
+// lessFunc returns the ascending order of the configured sort key, equal
+// namespaces are ordered by name
+func (o *NsOptions) lessFunc() func(a, b v1.Namespace) bool {
+	byName := func(a, b v1.Namespace) bool {
+		return a.GetName() < b.GetName()
+	}
+
+	switch o.sortBy {
+	case config.SortByCreation:
+		return func(a, b v1.Namespace) bool {
+			if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+				return a.CreationTimestamp.Before(&b.CreationTimestamp)
+			}
+			return byName(a, b)
+		}
+	case config.SortByPhase:
+		return func(a, b v1.Namespace) bool {
+			if a.Status.Phase != b.Status.Phase {
+				return a.Status.Phase < b.Status.Phase
+			}
+			return byName(a, b)
+		}
+	case config.SortByLastUsed:
+		lastUsed, err := o.lastUsed()
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to load history, sorting by name: %v\n", err)
+			return byName
+		}
+		return func(a, b v1.Namespace) bool {
+			ta, tb := lastUsed[a.GetName()], lastUsed[b.GetName()]
+			if !ta.Equal(tb) {
+				return ta.Before(tb)
+			}
+			return byName(a, b)
+		}
+	}
+	return byName
+}
+
+// lastUsed returns the time of the last switch to each namespace of the
+// current context
+func (o *NsOptions) lastUsed() (map[string]time.Time, error) {
+	store, err := history.NewDefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	lastUsed := map[string]time.Time{}
+	for _, e := range entries {
+		if e.Context == o.contextName() && e.Time.After(lastUsed[e.To]) {
+			lastUsed[e.To] = e.Time
+		}
+	}
+	return lastUsed, nil
+}
+
+// recentOrder returns the namespaces ordered by how recently and how
+// often they were used in the current context. Every switch to a namespace
+// adds to its score, older switches weigh less. Favorites stay first and
+// namespaces never used keep their order at the end.
+func (o *NsOptions) recentOrder(namespaces []string) ([]string, error) {
+	store, err := history.NewDefaultStore()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scores := map[string]float64{}
+	for _, e := range entries {
+		if e.Context == o.contextName() {
+			scores[e.To] += 1 / (1 + now.Sub(e.Time).Hours()/24)
+		}
+	}
+
+	s, err := o.loadState()
+	if err != nil {
+		return nil, err
+	}
+	// namespaces only known from the recently used namespaces, e.g. stored
+	// in the kubeconfig on another machine, follow in their order
+	recent := s.Recent[o.contextName()]
+	for i, ns := range recent {
+		if scores[ns] == 0 {
+			scores[ns] = float64(len(recent)-i) / 1000
+		}
+	}
+	result := append([]string{}, namespaces...)
+	sort.SliceStable(result, func(i, j int) bool {
+		fi, fj := s.IsFavorite(o.contextName(), result[i]), s.IsFavorite(o.contextName(), result[j])
+		if fi != fj {
+			return fi
+		}
+		return scores[result[i]] > scores[result[j]]
+	})
+	return result, nil
+}
+
+// isHidden reports whether the namespace is hidden in listings. System
+// namespaces are only hidden if no namespace argument is given and if they
+// are not the current namespace.
+func (o *NsOptions) isHidden(namespace string) bool {
+	if len(o.include) > 0 && !config.MatchAny(o.include, namespace) {
+		return true
+	}
+	if config.MatchAny(o.exclude, namespace) {
+		return true
+	}
+	if !o.hideSystem || o.userSpecifiedNamespace != "" || !o.config.IsSystem(namespace) {
+		return false
+	}
+	ctx, ok := o.rawConfig.Contexts[o.contextName()]
+	return !ok || ctx.Namespace != namespace
+}
+
+// isTerminating reports whether the namespace is in phase Terminating. Only
+// the namespace found by lookupNamespace or a list fetched during this
+// invocation are checked, the switch makes no API requests for it. The phase
+// of a cached list may be outdated and is not trusted, and with --force or
+// --offline the check is skipped.
+func (o *NsOptions) isTerminating(namespace string) bool {
+	if o.force || o.offline {
+		return false
+	}
+	if o.lookedUp != nil && o.lookedUp.GetName() == namespace {
+		return o.lookedUp.Status.Phase == v1.NamespaceTerminating
+	}
+	if o.namespaces == nil || o.listCached {
+		return false
+	}
+	for _, ns := range o.namespaces.Items {
+		if ns.GetName() == namespace {
+			return ns.Status.Phase == v1.NamespaceTerminating
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+)
+
+// supported keys of --group-by
+const (
+	groupByTenant  = "tenant"
+	groupByProject = "project"
+)
+
+// groupKeys lists the supported keys of --group-by
+var groupKeys = []string{groupByTenant, groupByProject}
+
+// validateGroupBy ensures that key is a supported group key, an empty key
+// disables grouping
+func validateGroupBy(key string) error {
+	if key == "" {
+		return nil
+	}
+	for _, k := range groupKeys {
+		if key == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid group key \"%s\", use one of %s", key, strings.Join(groupKeys, ", "))
+}
+
+// namespaceGroup returns the group of a namespace for the group key, an
+// empty group collects the namespaces without one
+func (o *NsOptions) namespaceGroup(ns v1.Namespace) string {
+	switch o.groupBy {
+	case groupByTenant:
+		return namespaceTenant(ns)
+	case groupByProject:
+		return namespaceProject(ns)
+	}
+	return ""
+}
+
+// printGrouped prints the namespaces below the name of their group, the
+// groups are sorted by name and keep the order of their namespaces.
+// Namespaces without a group are printed last.
+func (o *NsOptions) printGrouped(namespaces []v1.Namespace, currentNS string) error {
+	groups := map[string][]v1.Namespace{}
+	names := []string{}
+	for _, ns := range namespaces {
+		group := o.namespaceGroup(ns)
+		if _, ok := groups[group]; !ok && group != "" {
+			names = append(names, group)
+		}
+		groups[group] = append(groups[group], ns)
+	}
+	sort.Strings(names)
+	if _, ok := groups[""]; ok {
+		names = append(names, "")
+	}
+
+	plain := color.NoColor && !isTerminal(o.Out)
+	for i, group := range names {
+		if i > 0 {
+			fmt.Fprintln(o.Out)
+		}
+		if group == "" {
+			fmt.Fprintf(o.Out, "(no %s)\n", o.groupBy)
+		} else {
+			fmt.Fprintf(o.Out, "%s\n", group)
+		}
+		for _, ns := range groups[group] {
+			name := ns.GetName()
+			if !plain {
+				name = sprintStyled(o.namespaceStyle(ns, name == currentNS), name)
+			}
+			fmt.Fprintf(o.Out, "  %s\n", name)
+		}
+	}
+	return nil
+}
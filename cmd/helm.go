@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/metadata"
+)
+
+const (
+	// helmSelector selects the secrets in which Helm 3 stores the revisions
+	// of its releases
+	helmSelector = "owner=helm"
+	// helmReleaseType is the type of the release secrets
+	helmReleaseType = "helm.sh/release.v1"
+	// labelHelmName holds the name of the release
+	labelHelmName = "name"
+)
+
+// helmReleases returns the names of the Helm releases per namespace. Every
+// revision of a release is stored in its own secret, only the metadata of
+// the secrets is requested as they contain the whole chart. If listing the
+// secrets of all namespaces is forbidden, the ones of every namespace are
+// listed instead.
+func (o *NsOptions) helmReleases(namespaces []v1.Namespace) (map[string][]string, error) {
+	restConfig, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := metadata.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := o.releaseSecrets(client, metav1.NamespaceAll)
+	if apierrors.IsForbidden(err) {
+		secrets, err = o.namespacedReleaseSecrets(client, namespaces)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find Helm releases: %w", err)
+	}
+
+	seen := map[string]bool{}
+	releases := map[string][]string{}
+	for _, s := range secrets {
+		name := s.GetLabels()[labelHelmName]
+		if name == "" || seen[s.GetNamespace()+"/"+name] {
+			continue
+		}
+		seen[s.GetNamespace()+"/"+name] = true
+		releases[s.GetNamespace()] = append(releases[s.GetNamespace()], name)
+	}
+	for _, names := range releases {
+		sort.Strings(names)
+	}
+	return releases, nil
+}
+
+// releaseSecrets returns the metadata of the release secrets of namespace
+func (o *NsOptions) releaseSecrets(client metadata.Interface, namespace string) ([]metav1.PartialObjectMetadata, error) {
+	opts := metav1.ListOptions{LabelSelector: helmSelector, FieldSelector: "type=" + helmReleaseType}
+
+	var secrets *metav1.PartialObjectMetadataList
+	err := o.withRetry(func() (err error) {
+		secrets, err = client.Resource(v1.SchemeGroupVersion.WithResource("secrets")).Namespace(namespace).List(o.ctx, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Items, nil
+}
+
+// namespacedReleaseSecrets lists the release secrets of every namespace
+// concurrently. Namespaces whose secrets can't be listed either are skipped
+// with a warning, unless this is the case for all of them.
+func (o *NsOptions) namespacedReleaseSecrets(client metadata.Interface, namespaces []v1.Namespace) ([]metav1.PartialObjectMetadata, error) {
+	results := make([][]metav1.PartialObjectMetadata, len(namespaces))
+	errs := make([]error, len(namespaces))
+	forEachNamespace(namespaces, func(i int, ns v1.Namespace) {
+		results[i], errs[i] = o.releaseSecrets(client, ns.GetName())
+	})
+
+	secrets := []metav1.PartialObjectMetadata{}
+	forbidden := 0
+	var forbiddenErr error
+	for i, err := range errs {
+		switch {
+		case apierrors.IsForbidden(err):
+			forbidden++
+			forbiddenErr = err
+		case err != nil:
+			return nil, err
+		}
+		secrets = append(secrets, results[i]...)
+	}
+	if forbidden > 0 && forbidden == len(namespaces) {
+		return nil, forbiddenErr
+	}
+	if forbidden > 0 {
+		fmt.Fprintf(o.ErrOut, "warning: the secrets of %d namespaces can't be listed, their Helm releases are missing\n", forbidden)
+	}
+	return secrets, nil
+}
+
+// filterHelm removes all namespaces without Helm releases, the releases
+// are kept to mark the namespaces in the listing
+func (o *NsOptions) filterHelm() error {
+	releases, err := o.helmReleases(o.namespaces.Items)
+	if err != nil {
+		return err
+	}
+	o.namespaceReleases = releases
+
+	result := make([]v1.Namespace, 0, len(o.namespaces.Items))
+	for _, ns := range o.namespaces.Items {
+		if len(releases[ns.GetName()]) > 0 {
+			result = append(result, ns)
+		}
+	}
+	o.namespaces.Items = result
+	return nil
+}
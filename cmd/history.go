@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/postfinance/kubectl-ns/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyExample = `
+	# list all recorded namespace switches
+	kubectl ns history
+
+	# switch to the namespace of history entry 3
+	kubectl ns history --replay 3`
+)
+
+// HistoryOptions provides information required to list or replay the
+// namespace switch history
+type HistoryOptions struct {
+	ns     *NsOptions
+	store  *history.Store
+	replay int
+}
+
+// NewHistoryCmd provides a cobra command listing the namespace switch history
+func NewHistoryCmd(ns *NsOptions) *cobra.Command {
+	opt := &HistoryOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "history",
+		Short:        "Display/Replay namespace switches",
+		Example:      historyExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(func() error { return opt.Run(c) }); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&opt.replay, "replay", 0, "switch to the namespace of the given history entry")
+
+	return cmd
+}
+
+// Complete sets all information required for accessing the history
+func (o *HistoryOptions) Complete(cmd *cobra.Command, args []string) error {
+	var err error
+	o.store, err = history.NewDefaultStore()
+
+	return err
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *HistoryOptions) Validate() error {
+	if o.replay < 0 {
+		return fmt.Errorf("invalid history entry %d", o.replay)
+	}
+
+	return nil
+}
+
+// Run prints the history or switches to the namespace of a history entry
+func (o *HistoryOptions) Run(cmd *cobra.Command) error {
+	entries, err := o.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+
+	if o.replay == 0 {
+		return o.printHistory(entries)
+	}
+
+	if o.replay > len(entries) {
+		return fmt.Errorf("history entry %d does not exist", o.replay)
+	}
+	entry := entries[o.replay-1]
+
+	if err := o.ns.Complete(cmd, []string{entry.To}); err != nil {
+		return err
+	}
+	if entry.Context != o.ns.contextName() {
+		return fmt.Errorf("history entry %d belongs to context \"%s\", current context is \"%s\"",
+			o.replay, entry.Context, o.ns.contextName())
+	}
+	if err := o.ns.Validate(); err != nil {
+		return err
+	}
+
+	return o.ns.Run()
+}
+
+func (o *HistoryOptions) printHistory(entries []history.Entry) error {
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIME\tCONTEXT\tFROM\tTO")
+	for i, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", i+1, e.Time.Local().Format("2006-01-02 15:04:05"), e.Context, e.From, e.To)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// hncGroup is the API group of the Hierarchical Namespace Controller
+	hncGroup = "hnc.x-k8s.io"
+	// annotationSubnamespaceOf is set by HNC on namespaces created by a
+	// SubnamespaceAnchor, its value is the parent namespace
+	annotationSubnamespaceOf = "hnc.x-k8s.io/subnamespace-of"
+	// hncDepthLabelSuffix is the suffix of the labels HNC sets for every
+	// ancestor of a namespace, the value is the distance to the ancestor
+	hncDepthLabelSuffix = ".tree.hnc.x-k8s.io/depth"
+)
+
+// hierarchyResource returns the HierarchyConfiguration resource of the
+// preferred HNC API version, found is false if HNC is not installed
+func (o *NsOptions) hierarchyResource() (gvr schema.GroupVersionResource, found bool, err error) {
+	clientset, err := o.client()
+	if err != nil {
+		return gvr, false, err
+	}
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		return gvr, false, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	for _, g := range groups.Groups {
+		if g.Name == hncGroup {
+			return schema.GroupVersionResource{Group: hncGroup, Version: g.PreferredVersion.Version, Resource: "hierarchyconfigurations"}, true, nil
+		}
+	}
+	return gvr, false, nil
+}
+
+// namespaceParents returns the parent of every namespace in the hierarchy.
+// The parents are read from the HierarchyConfiguration objects, if listing
+// them is forbidden the ancestor labels of the namespaces are used.
+func (o *NsOptions) namespaceParents(namespaces []v1.Namespace) (map[string]string, error) {
+	gvr, found, err := o.hierarchyResource()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("--tree requires the Hierarchical Namespace Controller, the API group %s is not available", hncGroup)
+	}
+
+	client, err := o.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	var configs *unstructured.UnstructuredList
+	err = o.withRetry(func() (err error) {
+		configs, err = client.Resource(gvr).List(o.ctx, metav1.ListOptions{})
+		return err
+	})
+	switch {
+	case apierrors.IsForbidden(err):
+		return labelParents(namespaces), nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to list hierarchy configurations: %w", err)
+	}
+
+	parents := map[string]string{}
+	for _, c := range configs.Items {
+		parent, _, _ := unstructured.NestedString(c.Object, "spec", "parent")
+		if parent != "" {
+			parents[c.GetNamespace()] = parent
+		}
+	}
+	return parents, nil
+}
+
+// labelParents derives the parents from the ancestor labels HNC sets on
+// every namespace, the parent is the ancestor with depth 1
+func labelParents(namespaces []v1.Namespace) map[string]string {
+	parents := map[string]string{}
+	for _, ns := range namespaces {
+		for key, value := range ns.GetLabels() {
+			if strings.HasSuffix(key, hncDepthLabelSuffix) && value == "1" {
+				parents[ns.GetName()] = strings.TrimSuffix(key, hncDepthLabelSuffix)
+			}
+		}
+	}
+	return parents
+}
+
+// printTree prints the namespaces as an indented hierarchy, namespaces
+// whose parent is not listed are shown as roots. Subnamespaces are marked
+// with [s].
+func (o *NsOptions) printTree(namespaces []v1.Namespace) error {
+	parents, err := o.namespaceParents(namespaces)
+	if err != nil {
+		return err
+	}
+	currentNS := o.rawConfig.Contexts[o.contextName()].Namespace
+
+	listed := map[string]bool{}
+	for _, ns := range namespaces {
+		listed[ns.GetName()] = true
+	}
+	roots := []v1.Namespace{}
+	children := map[string][]v1.Namespace{}
+	for _, ns := range namespaces {
+		parent := parents[ns.GetName()]
+		if parent == "" || !listed[parent] {
+			roots = append(roots, ns)
+			continue
+		}
+		children[parent] = append(children[parent], ns)
+	}
+
+	plain := color.NoColor && !isTerminal(o.Out)
+	subnamespaces := false
+	var printLevel func(level []v1.Namespace, indent string, root bool)
+	printLevel = func(level []v1.Namespace, indent string, root bool) {
+		for i, ns := range level {
+			branch, next := "", ""
+			switch {
+			case root:
+			case i == len(level)-1:
+				branch, next = "└── ", "    "
+			default:
+				branch, next = "├── ", "│   "
+			}
+
+			name := ns.GetName()
+			if !plain {
+				name = sprintStyled(o.namespaceStyle(ns, ns.GetName() == currentNS), name)
+			}
+			if _, ok := ns.GetAnnotations()[annotationSubnamespaceOf]; ok {
+				subnamespaces = true
+				name = "[s] " + name
+			}
+			fmt.Fprintf(o.Out, "%s%s%s\n", indent, branch, name)
+			printLevel(children[ns.GetName()], indent+next, false)
+		}
+	}
+	printLevel(roots, "", true)
+
+	if subnamespaces {
+		fmt.Fprintln(o.Out, "\n[s] indicates subnamespaces")
+	}
+	return nil
+}
+
+// subnamespaceByShortName returns the subnamespace whose name without the
+// prefix of its parent equals the user specified namespace, ok is false if
+// no or several subnamespaces match
+func (o *NsOptions) subnamespaceByShortName(namespaces []v1.Namespace) (name string, ok bool) {
+	matches := []string{}
+	for _, ns := range namespaces {
+		parent, found := ns.GetAnnotations()[annotationSubnamespaceOf]
+		if !found || !strings.HasPrefix(ns.GetName(), parent+"-") {
+			continue
+		}
+		if strings.TrimPrefix(ns.GetName(), parent+"-") == o.userSpecifiedNamespace {
+			matches = append(matches, ns.GetName())
+		}
+	}
+	if len(matches) != 1 {
+		return "", false
+	}
+	return matches[0], true
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookExample = `
+	# switch to the pinned namespace when changing the directory in bash
+	echo 'source <(kubectl ns hook bash)' >> ~/.bashrc
+
+	# switch to the pinned namespace when changing the directory in zsh
+	echo 'source <(kubectl ns hook zsh)' >> ~/.zshrc`
+)
+
+// hookScripts are the shell snippets running kubectl ns --auto whenever the
+// working directory changes
+var hookScripts = map[string]string{
+	"bash": `_kubectl_ns_auto() {
+  if [ "$PWD" != "$_KUBECTL_NS_DIR" ]; then
+    _KUBECTL_NS_DIR="$PWD"
+    kubectl ns --auto
+  fi
+}
+PROMPT_COMMAND="_kubectl_ns_auto${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+`,
+	"zsh": `_kubectl_ns_auto() {
+  kubectl ns --auto
+}
+autoload -U add-zsh-hook
+add-zsh-hook chpwd _kubectl_ns_auto
+_kubectl_ns_auto
+`,
+}
+
+// NewHookCmd provides a cobra command printing a shell hook which switches
+// to the namespace pinned by a project file
+func NewHookCmd(ns *NsOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "hook bash|zsh",
+		Short:                 "Print a shell hook switching to pinned namespaces",
+		Example:               hookExample,
+		DisableFlagsInUseLine: true,
+		ValidArgs:             []string{"bash", "zsh"},
+		Args:                  cobra.ExactValidArgs(1),
+		SilenceUsage:          true,
+		RunE: func(c *cobra.Command, args []string) error {
+			script, ok := hookScripts[args[0]]
+			if !ok {
+				return fmt.Errorf("unsupported shell \"%s\"", args[0])
+			}
+			_, err := fmt.Fprint(ns.Out, script)
+			return err
+		},
+	}
+	return cmd
+}
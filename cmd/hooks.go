@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// switchEvent describes a namespace switch for hooks and notifications
+type switchEvent struct {
+	FromContext   string
+	ToContext     string
+	FromNamespace string
+	ToNamespace   string
+}
+
+// environ returns the environment of hook commands
+func (e switchEvent) environ() []string {
+	return append(os.Environ(),
+		"KUBECTL_NS_FROM_CONTEXT="+e.FromContext,
+		"KUBECTL_NS_TO_CONTEXT="+e.ToContext,
+		"KUBECTL_NS_FROM_NAMESPACE="+e.FromNamespace,
+		"KUBECTL_NS_TO_NAMESPACE="+e.ToNamespace,
+	)
+}
+
+// preSwitch runs the pre-switch hooks, an error vetoes the switch
+func (o *NsOptions) preSwitch(e switchEvent, w io.Writer) error {
+	for _, command := range o.config.Hooks.PreSwitch {
+		if err := runHook(command, e, w); err != nil {
+			return fmt.Errorf("switch vetoed by the pre-switch hook \"%s\": %w", command, err)
+		}
+	}
+	return nil
+}
+
+// postSwitch records the switch in the audit log, runs the post-switch
+// hooks and notifies the webhook. Failures are reported as warnings since
+// the switch already happened.
+func (o *NsOptions) postSwitch(e switchEvent, w io.Writer) {
+	o.recordAudit(e, w)
+	for _, command := range o.config.Hooks.PostSwitch {
+		if err := runHook(command, e, w); err != nil {
+			fmt.Fprintf(w, "warning: post-switch hook \"%s\" failed: %v\n", command, err)
+		}
+	}
+	o.notifyWebhook(e, w)
+}
+
+// runHook runs command with the shell of the platform, its output is
+// written to w
+func runHook(command string, e switchEvent, w io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+	cmd.Env = e.environ()
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// inClusterContext is the name of the context used inside a pod
+	inClusterContext = "in-cluster"
+	// serviceAccountNamespaceFile contains the namespace of the pod
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+	// namespaceEnv is the environment variable holding the namespace
+	// selected inside a pod
+	namespaceEnv = "KUBECTL_NS_NAMESPACE"
+)
+
+// detectInCluster replaces an empty KUBECONFIG by a context for the cluster
+// the pod runs in. Its namespace is taken from KUBECTL_NS_NAMESPACE or the
+// namespace of the service account.
+func (o *NsOptions) detectInCluster() {
+	if len(o.rawConfig.Contexts) > 0 || *o.configFlags.Context != "" {
+		return
+	}
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return
+	}
+	if _, err := rest.InClusterConfig(); err != nil {
+		return
+	}
+
+	namespace := os.Getenv(namespaceEnv)
+	if namespace == "" {
+		data, _ := ioutil.ReadFile(serviceAccountNamespaceFile)
+		namespace = strings.TrimSpace(string(data))
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	config := api.NewConfig()
+	config.Clusters[inClusterContext] = &api.Cluster{Server: "https://" + net.JoinHostPort(host, port)}
+	config.AuthInfos[inClusterContext] = &api.AuthInfo{}
+	config.Contexts[inClusterContext] = &api.Context{Cluster: inClusterContext, AuthInfo: inClusterContext, Namespace: namespace}
+	config.CurrentContext = inClusterContext
+	o.rawConfig = *config
+	o.inCluster = true
+}
+
+// inClusterRESTConfig returns the client configuration of the service
+// account of the pod, impersonation is applied like with a kubeconfig
+func (o *NsOptions) inClusterRESTConfig() (*rest.Config, error) {
+	restConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	if timeout, err := time.ParseDuration(*o.configFlags.Timeout); err == nil {
+		restConfig.Timeout = timeout
+	}
+	restConfig.Impersonate.UserName, restConfig.Impersonate.Groups = o.impersonation()
+	return restConfig, nil
+}
+
+// printInClusterNamespace prints the namespace selected inside a pod as
+// shell variable, there is no kubeconfig to write it to
+func (o *NsOptions) printInClusterNamespace(config api.Config) error {
+	ctx, ok := config.Contexts[inClusterContext]
+	if !ok {
+		return nil
+	}
+	if o.dryRun {
+		fmt.Fprintf(o.Out, "context \"%s\": namespace -> \"%s\" (%s)\n", inClusterContext, ctx.Namespace, namespaceEnv)
+		return nil
+	}
+	fmt.Fprintf(o.Out, "export %s=%s\n", namespaceEnv, ctx.Namespace)
+	return nil
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+)
+
+// inventoryItem lists all objects of one resource type in a namespace
+type inventoryItem struct {
+	Resource schema.GroupVersionResource
+	Kind     string
+	Objects  []unstructured.Unstructured
+}
+
+// Name returns the resource name qualified with its group like kubectl
+// prints it, e.g. deployments.apps
+func (i inventoryItem) Name() string {
+	if i.Resource.Group == "" {
+		return i.Resource.Resource
+	}
+	return i.Resource.Resource + "." + i.Resource.Group
+}
+
+// dynamicClient returns a dynamic client for the API server
+func (o *NsOptions) dynamicClient() (dynamic.Interface, error) {
+	restConfig, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(restConfig)
+}
+
+// namespaceInventory lists the objects of all namespaced resource types in
+// namespace, resource types without objects and events are omitted
+func (o *NsOptions) namespaceInventory(namespace string) ([]inventoryItem, error) {
+	clientset, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := o.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := namespacedResources(clientset.Discovery())
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := []inventoryItem{}
+	for gvr, kind := range resources {
+		list, err := dyn.Resource(gvr).Namespace(namespace).List(o.ctx, metav1.ListOptions{})
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(list.Items) == 0 {
+			continue
+		}
+		inventory = append(inventory, inventoryItem{
+			Resource: gvr,
+			Kind:     kind,
+			Objects:  list.Items,
+		})
+	}
+	sort.Slice(inventory, func(i, j int) bool {
+		return inventory[i].Name() < inventory[j].Name()
+	})
+
+	return inventory, nil
+}
+
+// namespacedResources returns the preferred version of all namespaced
+// resource types which can be listed, mapped to their kind
+func namespacedResources(client discovery.DiscoveryInterface) (map[schema.GroupVersionResource]string, error) {
+	lists, err := client.ServerPreferredNamespacedResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, err
+	}
+
+	resources := map[schema.GroupVersionResource]string{}
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") || !hasVerb(r.Verbs, "list") {
+				continue
+			}
+			if r.Name == "events" {
+				continue
+			}
+			resources[gv.WithResource(r.Name)] = r.Kind
+		}
+	}
+	return resources, nil
+}
+
+func hasVerb(verbs metav1.Verbs, verb string) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/postfinance/kubectl-ns/pkg/backup"
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigPaths returns the kubeconfig files in the order of their
+// precedence, either the file of --kubeconfig or the files of KUBECONFIG
+func (o *NsOptions) kubeconfigPaths() []string {
+	if len(o.kubeconfigs) > 0 {
+		return o.kubeconfigs
+	}
+	if o.configFlags.KubeConfig != nil && *o.configFlags.KubeConfig != "" {
+		return []string{*o.configFlags.KubeConfig}
+	}
+	return clientcmd.NewDefaultPathOptions().GetLoadingPrecedence()
+}
+
+// startingKubeconfig reads the merged kubeconfig from disk, the origin of
+// every entry is recorded in its LocationOfOrigin
+func (o *NsOptions) startingKubeconfig() (*api.Config, error) {
+	rules := &clientcmd.ClientConfigLoadingRules{Precedence: o.kubeconfigPaths()}
+	return rules.Load()
+}
+
+// kubeconfigEdit is a change of a single kubeconfig file
+type kubeconfigEdit struct {
+	description string
+	apply       func(*api.Config)
+}
+
+// modifyKubeconfig writes the changed namespaces, extensions and current
+// context of config to the kubeconfig files. A context is changed in the
+// file which defines it and the current context in the first file setting
+// one, unless --write-to selects the file. The files are backed up before
+// the first modification of an invocation and replaced atomically, symlinks
+// and permissions are kept. With --dry-run the changes are only printed,
+// inside a pod without kubeconfig the namespace is printed as variable.
+func (o *NsOptions) modifyKubeconfig(config api.Config) error {
+	if o.inCluster {
+		return o.printInClusterNamespace(config)
+	}
+
+	paths := o.kubeconfigPaths()
+	starting, err := o.startingKubeconfig()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	edits := map[string][]kubeconfigEdit{}
+	for _, name := range names {
+		ctx := config.Contexts[name]
+		existing, ok := starting.Contexts[name]
+		if ok && existing.Namespace == ctx.Namespace && reflect.DeepEqual(existing.Extensions, ctx.Extensions) {
+			continue
+		}
+		file := o.contextFile(existing, paths)
+		name := name
+		edits[file] = append(edits[file], kubeconfigEdit{
+			description: contextChange(name, existing, ctx),
+			apply: func(c *api.Config) {
+				if target, ok := c.Contexts[name]; ok {
+					target.Namespace = ctx.Namespace
+					target.Extensions = ctx.Extensions
+					return
+				}
+				c.Contexts[name] = ctx.DeepCopy()
+			},
+		})
+	}
+	if config.CurrentContext != starting.CurrentContext {
+		file, err := o.currentContextFile(paths)
+		if err != nil {
+			return err
+		}
+		edits[file] = append(edits[file], kubeconfigEdit{
+			description: fmt.Sprintf("current context: \"%s\" -> \"%s\"", starting.CurrentContext, config.CurrentContext),
+			apply: func(c *api.Config) {
+				c.CurrentContext = config.CurrentContext
+			},
+		})
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+
+	files := make([]string, 0, len(edits))
+	for file := range edits {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	if o.dryRun {
+		for _, file := range files {
+			for _, edit := range edits[file] {
+				fmt.Fprintf(o.Out, "%s (%s)\n", edit.description, file)
+			}
+		}
+		return nil
+	}
+
+	if o.writeTo != "" && !containsString(paths, o.writeTo) {
+		fmt.Fprintf(o.ErrOut, "warning: %s is not part of the KUBECONFIG, the change has no effect until it is added\n", o.writeTo)
+	}
+	if !o.backedUp {
+		if err := o.backupKubeconfig(paths); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to back up the kubeconfig: %v\n", err)
+		}
+		o.backedUp = true
+	}
+
+	for _, file := range files {
+		c, err := loadKubeconfigFile(file)
+		if err != nil {
+			return err
+		}
+		for _, edit := range edits[file] {
+			edit.apply(c)
+		}
+		data, err := clientcmd.Write(*c)
+		if err != nil {
+			return err
+		}
+		if err := safefile.WriteFile(file, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// contextChange describes the change of a context, existing is nil for a
+// new context
+func contextChange(name string, existing, ctx *api.Context) string {
+	if existing == nil {
+		return fmt.Sprintf("context \"%s\": added with namespace \"%s\"", name, ctx.Namespace)
+	}
+	if existing.Namespace == ctx.Namespace {
+		return fmt.Sprintf("context \"%s\": extensions changed", name)
+	}
+	return fmt.Sprintf("context \"%s\": namespace \"%s\" -> \"%s\"", name, namespaceOrDefault(existing.Namespace), ctx.Namespace)
+}
+
+// namespaceOrDefault returns the namespace used for an empty namespace
+func namespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+	return namespace
+}
+
+// contextFile returns the file a change of the context is written to
+func (o *NsOptions) contextFile(ctx *api.Context, paths []string) string {
+	switch {
+	case o.writeTo != "":
+		return o.writeTo
+	case ctx != nil && ctx.LocationOfOrigin != "":
+		return ctx.LocationOfOrigin
+	}
+	return paths[0]
+}
+
+// currentContextFile returns the file the current context is written to,
+// the first file which sets a current context takes precedence when the
+// files are merged
+func (o *NsOptions) currentContextFile(paths []string) (string, error) {
+	if o.writeTo != "" {
+		return o.writeTo, nil
+	}
+	for _, path := range paths {
+		c, err := loadKubeconfigFile(path)
+		if err != nil {
+			return "", err
+		}
+		if c.CurrentContext != "" {
+			return path, nil
+		}
+	}
+	return paths[0], nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// loadKubeconfigFile reads a single kubeconfig file, a missing file results
+// in an empty configuration
+func loadKubeconfigFile(path string) (*api.Config, error) {
+	c, err := clientcmd.LoadFromFile(path)
+	if os.IsNotExist(err) {
+		return api.NewConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// backupKubeconfig copies the kubeconfig files into a new backup unless
+// backups are disabled
+func (o *NsOptions) backupKubeconfig(paths []string) error {
+	keep := o.backupsKept()
+	if keep == 0 {
+		return nil
+	}
+	store, err := backup.NewDefaultStore(keep)
+	if err != nil {
+		return err
+	}
+	_, err = store.Create(paths)
+	return err
+}
+
+// backupsKept returns the configured number of kubeconfig backups
+func (o *NsOptions) backupsKept() int {
+	if o.config != nil && o.config.Backups != nil {
+		return *o.config.Backups
+	}
+	return backup.DefaultKeep
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	labelExample = `
+	# label the namespace foo with team=payments and env=dev
+	kubectl ns label foo team=payments env=dev
+
+	# change the team label of the namespace foo and remove its env label
+	kubectl ns label foo team=checkout env- --overwrite`
+)
+
+// metadataChange are the labels or annotations to set and to remove, it is
+// parsed from arguments like key=value and key-
+type metadataChange struct {
+	set    map[string]string
+	remove []string
+}
+
+// parseMetadataChange parses the key=value and key- arguments, validate
+// returns the errors of a key and its value
+func parseMetadataChange(args []string, kind string, validate func(key, value string) []string) (metadataChange, error) {
+	change := metadataChange{set: map[string]string{}}
+	for _, arg := range args {
+		if strings.HasSuffix(arg, "-") && !strings.Contains(arg, "=") {
+			key := strings.TrimSuffix(arg, "-")
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return change, fmt.Errorf("invalid %s key \"%s\": %s", kind, key, strings.Join(errs, "; "))
+			}
+			change.remove = append(change.remove, key)
+			continue
+		}
+
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return change, fmt.Errorf("invalid %s \"%s\", use key=value to set it or key- to remove it", kind, arg)
+		}
+		if errs := validate(parts[0], parts[1]); len(errs) > 0 {
+			return change, fmt.Errorf("invalid %s \"%s\": %s", kind, arg, strings.Join(errs, "; "))
+		}
+		if _, ok := change.set[parts[0]]; ok {
+			return change, fmt.Errorf("%s \"%s\" is set more than once", kind, parts[0])
+		}
+		change.set[parts[0]] = parts[1]
+	}
+	for _, key := range change.remove {
+		if _, ok := change.set[key]; ok {
+			return change, fmt.Errorf("%s \"%s\" can't be set and removed at once", kind, key)
+		}
+	}
+	return change, nil
+}
+
+// patch returns the merge patch of the current labels or annotations,
+// removed keys are null. Existing values are only changed with overwrite,
+// keys to remove which don't exist are reported to w. The patch is nil if
+// nothing changes.
+func (c metadataChange) patch(current map[string]string, kind string, overwrite bool, w io.Writer) (map[string]interface{}, error) {
+	patch := map[string]interface{}{}
+	for key, value := range c.set {
+		existing, ok := current[key]
+		if ok && existing == value {
+			continue
+		}
+		if ok && !overwrite {
+			return nil, fmt.Errorf("%s \"%s\" already has the value \"%s\", use --overwrite to change it", kind, key, existing)
+		}
+		patch[key] = value
+	}
+	for _, key := range c.remove {
+		if _, ok := current[key]; !ok {
+			fmt.Fprintf(w, "warning: %s \"%s\" not found\n", kind, key)
+			continue
+		}
+		patch[key] = nil
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// LabelOptions provides information required to change the labels of a
+// namespace
+type LabelOptions struct {
+	ns   *NsOptions
+	name string
+
+	change    metadataChange
+	overwrite bool
+}
+
+// NewLabelCmd provides a cobra command adding and removing the labels of a
+// namespace
+func NewLabelCmd(ns *NsOptions) *cobra.Command {
+	opt := &LabelOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "label namespace key=value... [key-...]",
+		Short:        "Add or remove the labels of a namespace",
+		Example:      labelExample,
+		Args:         cobra.MinimumNArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.overwrite, "overwrite", false, "change the value of labels which are already set")
+
+	return cmd
+}
+
+// Complete sets all information required for changing the labels
+func (o *LabelOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+
+	var err error
+	o.change, err = parseMetadataChange(args[1:], "label", func(key, value string) []string {
+		return append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if target, ok := o.ns.config.Aliases[o.name]; ok {
+		o.name = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *LabelOptions) Validate() error {
+	return o.ns.checkContext()
+}
+
+// Run patches the labels of the namespace, --dry-run prints the patch
+func (o *LabelOptions) Run() error {
+	return o.ns.patchMetadata(o.name, "labels", func(ns *v1.Namespace) (map[string]interface{}, error) {
+		return o.change.patch(ns.GetLabels(), "label", o.overwrite, o.ns.ErrOut)
+	})
+}
+
+// patchMetadata applies the merge patch of the labels or annotations of the
+// namespace returned by patch, field is the name of the metadata field
+func (o *NsOptions) patchMetadata(name, field string, patch func(ns *v1.Namespace) (map[string]interface{}, error)) error {
+	clientset, err := o.client()
+	if err != nil {
+		return err
+	}
+	namespaces := clientset.CoreV1().Namespaces()
+
+	var ns *v1.Namespace
+	err = o.withRetry(func() (err error) {
+		ns, err = namespaces.Get(o.ctx, name, metav1.GetOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	changes, err := patch(ns)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		fmt.Fprintf(o.Out, "%s of namespace \"%s\" unchanged\n", field, name)
+		return nil
+	}
+	data, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{field: changes}})
+	if err != nil {
+		return err
+	}
+	if o.dryRun {
+		fmt.Fprintf(o.Out, "namespace \"%s\" would be patched with %s\n", name, data)
+		return nil
+	}
+
+	err = o.withRetry(func() error {
+		_, err := namespaces.Patch(o.ctx, name, types.MergePatchType, data, metav1.PatchOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to patch namespace: %w", err)
+	}
+	fmt.Fprintf(o.Out, "%s of namespace \"%s\" updated\n", field, name)
+	return nil
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/postfinance/kubectl-ns/pkg/oidc"
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// keys of the configuration of the oidc auth provider
+const (
+	oidcIssuerURL   = "idp-issuer-url"
+	oidcClientID    = "client-id"
+	oidcSecret      = "client-secret"
+	oidcCAFile      = "idp-certificate-authority"
+	oidcCAData      = "idp-certificate-authority-data"
+	oidcExtraScopes = "extra-scopes"
+	oidcIDToken     = "id-token"
+	oidcRefresh     = "refresh-token"
+)
+
+// oidcAuthInfo returns the name and configuration of the user of the
+// current context if it authenticates by the oidc auth provider
+func (o *NsOptions) oidcAuthInfo() (string, *api.AuthInfo, bool) {
+	ctx, ok := o.rawConfig.Contexts[o.contextName()]
+	if !ok {
+		return "", nil, false
+	}
+	name := flagValue(o.configFlags.AuthInfoName, ctx.AuthInfo)
+	authInfo, ok := o.rawConfig.AuthInfos[name]
+	if !ok || authInfo.AuthProvider == nil || authInfo.AuthProvider.Name != "oidc" {
+		return "", nil, false
+	}
+	return name, authInfo, true
+}
+
+// usesLogin reports whether the named context has the user logged in during
+// this invocation, the token of the login applies to it
+func (o *NsOptions) usesLogin(name string) bool {
+	if o.loginToken == "" {
+		return false
+	}
+	ctx, ok := o.rawConfig.Contexts[name]
+	if !ok {
+		return false
+	}
+	user, _, _ := o.oidcAuthInfo()
+	return flagValue(o.configFlags.AuthInfoName, ctx.AuthInfo) == user
+}
+
+// oidcSessionExpired reports whether err was caused by an oidc user whose
+// tokens could not be refreshed and the device login is enabled
+func (o *NsOptions) oidcSessionExpired(err error) bool {
+	if o.config == nil || o.config.OIDCDeviceLogin == nil || !*o.config.OIDCDeviceLogin || o.loginToken != "" {
+		return false
+	}
+	if _, _, ok := o.oidcAuthInfo(); !ok {
+		return false
+	}
+	msg := err.Error()
+	return apierrors.IsUnauthorized(err) ||
+		strings.Contains(msg, "failed to refresh token") ||
+		strings.Contains(msg, "cannot refresh without refresh-token")
+}
+
+// withLogin runs fn again after logging in by the device authorization
+// grant if it failed because the oidc session of the user expired. Every
+// command accessing the API server runs through it.
+func (o *NsOptions) withLogin(fn func() error) error {
+	err := fn()
+	if err == nil || !o.oidcSessionExpired(err) {
+		return err
+	}
+	if loginErr := o.oidcLogin(); loginErr != nil {
+		return fmt.Errorf("%v, the login failed: %w", err, loginErr)
+	}
+	return fn()
+}
+
+// oidcLogin logs the user of the current context in again by the device
+// authorization grant. The new tokens are written to the kubeconfig and
+// used by all following requests.
+func (o *NsOptions) oidcLogin() error {
+	name, authInfo, _ := o.oidcAuthInfo()
+	cfg := authInfo.AuthProvider.Config
+
+	client, err := oidcHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+	provider := &oidc.Provider{
+		IssuerURL:    cfg[oidcIssuerURL],
+		ClientID:     cfg[oidcClientID],
+		ClientSecret: cfg[oidcSecret],
+		Client:       client,
+	}
+	if scopes := cfg[oidcExtraScopes]; scopes != "" {
+		provider.Scopes = strings.Split(scopes, ",")
+	}
+
+	fmt.Fprintf(o.ErrOut, "the session of user \"%s\" expired, log in again\n", name)
+	authorization, err := provider.Authorize(o.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start the login: %w", err)
+	}
+	fmt.Fprintf(o.ErrOut, "open %s and enter the code %s\n", authorization.URI(), authorization.UserCode)
+	token, err := provider.Poll(o.ctx, authorization)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.ErrOut, "logged in")
+
+	o.loginToken = token.IDToken
+	o.clientset = nil
+	if o.dryRun {
+		return nil
+	}
+	return o.saveOIDCTokens(name, authInfo, token)
+}
+
+// saveOIDCTokens writes the tokens to the file defining the user
+func (o *NsOptions) saveOIDCTokens(name string, authInfo *api.AuthInfo, token *oidc.Token) error {
+	file := authInfo.LocationOfOrigin
+	if file == "" {
+		file = o.kubeconfigPaths()[0]
+	}
+	c, err := loadKubeconfigFile(file)
+	if err != nil {
+		return err
+	}
+	target, ok := c.AuthInfos[name]
+	if !ok || target.AuthProvider == nil {
+		return fmt.Errorf("user \"%s\" not found in %s", name, file)
+	}
+	target.AuthProvider.Config[oidcIDToken] = token.IDToken
+	if token.RefreshToken != "" {
+		target.AuthProvider.Config[oidcRefresh] = token.RefreshToken
+	}
+	data, err := clientcmd.Write(*c)
+	if err != nil {
+		return err
+	}
+	if err := safefile.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
+
+// oidcHTTPClient returns a client trusting the certificate authority of the
+// oidc provider configuration
+func oidcHTTPClient(cfg map[string]string) (*http.Client, error) {
+	tlsConfig := rest.TLSClientConfig{CAFile: cfg[oidcCAFile]}
+	if data := cfg[oidcCAData]; data != "" {
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", oidcCAData, err)
+		}
+		tlsConfig.CAData = decoded
+	}
+	transport, err := rest.TransportFor(&rest.Config{TLSClientConfig: tlsConfig})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
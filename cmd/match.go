@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// isPattern reports whether the user specified namespace is a shell
+// pattern or a regular expression instead of a (partial) name
+func (o *NsOptions) isPattern() bool {
+	return o.regex || strings.ContainsAny(o.userSpecifiedNamespace, "*?[")
+}
+
+// compilePattern validates the user specified namespace if it is a shell
+// pattern or a regular expression
+func (o *NsOptions) compilePattern() error {
+	if o.prefix && (o.userSpecifiedNamespace == "" || o.fuzzy || o.isPattern()) {
+		return fmt.Errorf("--prefix requires a namespace argument and can't be combined with patterns or --fuzzy")
+	}
+	if !o.isPattern() {
+		return nil
+	}
+	if o.userSpecifiedNamespace == "" {
+		return fmt.Errorf("--regex requires a namespace argument")
+	}
+	if o.force || o.create || o.fuzzy {
+		return fmt.Errorf("patterns can't be combined with --force, --create or --fuzzy")
+	}
+
+	if o.regex {
+		var err error
+		if o.pattern, err = regexp.Compile(o.userSpecifiedNamespace); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return nil
+	}
+	if _, err := path.Match(o.userSpecifiedNamespace, ""); err != nil {
+		return fmt.Errorf("invalid pattern \"%s\": %w", o.userSpecifiedNamespace, err)
+	}
+	return nil
+}
+
+// matches reports whether name matches the user specified namespace. It is
+// either a substring of name, a shell pattern matching the whole name, with
+// --regex a regular expression, with --fuzzy a subsequence of name or with
+// --prefix the start of name.
+func (o *NsOptions) matches(name string) bool {
+	switch {
+	case o.prefix:
+		return strings.HasPrefix(name, o.userSpecifiedNamespace)
+	case o.fuzzy:
+		_, ok := fuzzyScore(o.userSpecifiedNamespace, name)
+		return ok
+	case o.pattern != nil:
+		return o.pattern.MatchString(name)
+	case o.isPattern():
+		ok, _ := path.Match(o.userSpecifiedNamespace, name)
+		return ok
+	}
+	return strings.Contains(name, o.userSpecifiedNamespace)
+}
+
+// pastPrefix reports whether no namespace after name can match the prefix.
+// The API server lists namespaces ordered by name, so the listing can stop
+// at the first name which sorts after all names starting with the prefix.
+func (o *NsOptions) pastPrefix(name string) bool {
+	return o.prefix && name > o.userSpecifiedNamespace && !strings.HasPrefix(name, o.userSpecifiedNamespace)
+}
+
+// bestFuzzyMatch returns the namespace with the highest fuzzy score, an
+// error lists the candidates if several namespaces share the best score
+func (o *NsOptions) bestFuzzyMatch(namespaces []v1.Namespace) (string, error) {
+	best, candidates := 0, []string{}
+	for _, ns := range namespaces {
+		score, ok := fuzzyScore(o.userSpecifiedNamespace, ns.GetName())
+		switch {
+		case !ok, len(candidates) > 0 && score < best:
+			continue
+		case len(candidates) == 0 || score > best:
+			best, candidates = score, []string{ns.GetName()}
+		default:
+			candidates = append(candidates, ns.GetName())
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("can't change namespace, no namespace matches \"%s\"", o.userSpecifiedNamespace)
+	case 1:
+		return candidates[0], nil
+	}
+	return "", fmt.Errorf("can't change namespace, \"%s\" is ambiguous: %s", o.userSpecifiedNamespace, strings.Join(candidates, ", "))
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// metricsGroup is the API group served by metrics-server
+const metricsGroup = "metrics.k8s.io"
+
+// resourceUsage is the summed CPU and memory usage of pods
+type resourceUsage struct {
+	cpu    resource.Quantity
+	memory resource.Quantity
+	pods   int
+}
+
+// add sums the usage of the containers of a PodMetrics object
+func (u *resourceUsage) add(pod unstructured.Unstructured) {
+	u.pods++
+	containers, _, _ := unstructured.NestedSlice(pod.Object, "containers")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(container, "usage")
+		if q, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+			u.cpu.Add(q)
+		}
+		if q, err := resource.ParseQuantity(usage["memory"]); err == nil {
+			u.memory.Add(q)
+		}
+	}
+}
+
+// metricsResource returns the PodMetrics resource of the preferred metrics
+// API version, found is false if metrics-server is not installed
+func (o *NsOptions) metricsResource() (gvr schema.GroupVersionResource, found bool, err error) {
+	clientset, err := o.client()
+	if err != nil {
+		return gvr, false, err
+	}
+	groups, err := clientset.Discovery().ServerGroups()
+	if err != nil {
+		return gvr, false, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+	for _, g := range groups.Groups {
+		if g.Name == metricsGroup {
+			return schema.GroupVersionResource{Group: metricsGroup, Version: g.PreferredVersion.Version, Resource: "pods"}, true, nil
+		}
+	}
+	return gvr, false, nil
+}
+
+// namespaceUsage returns the live usage of the pods of the namespaces by
+// namespace, found is false if the metrics API is not available. The
+// metrics of all namespaces are requested at once, if this is forbidden
+// every namespace is requested on its own.
+func (o *NsOptions) namespaceUsage(namespaces []v1.Namespace) (usage map[string]*resourceUsage, found bool, err error) {
+	gvr, found, err := o.metricsResource()
+	if err != nil || !found {
+		return nil, found, err
+	}
+	client, err := o.dynamicClient()
+	if err != nil {
+		return nil, true, err
+	}
+
+	usage = map[string]*resourceUsage{}
+	for _, ns := range namespaces {
+		usage[ns.GetName()] = &resourceUsage{}
+	}
+
+	var list *unstructured.UnstructuredList
+	err = o.withRetry(func() (err error) {
+		list, err = client.Resource(gvr).List(o.ctx, metav1.ListOptions{})
+		return err
+	})
+	switch {
+	case err == nil:
+		for _, pod := range list.Items {
+			if u, ok := usage[pod.GetNamespace()]; ok {
+				u.add(pod)
+			}
+		}
+		return usage, true, nil
+	case !apierrors.IsForbidden(err):
+		return nil, true, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+
+	lists := make([]*unstructured.UnstructuredList, len(namespaces))
+	forEachNamespace(namespaces, func(i int, ns v1.Namespace) {
+		_ = o.withRetry(func() (err error) {
+			lists[i], err = client.Resource(gvr).Namespace(ns.GetName()).List(o.ctx, metav1.ListOptions{})
+			return err
+		})
+	})
+	for i, ns := range namespaces {
+		// namespaces without permissions have no usage
+		if lists[i] == nil {
+			delete(usage, ns.GetName())
+			continue
+		}
+		for _, pod := range lists[i].Items {
+			usage[ns.GetName()].add(pod)
+		}
+	}
+	return usage, true, nil
+}
+
+// formatCPU prints CPU in millicores like kubectl top
+func formatCPU(q resource.Quantity) string {
+	return fmt.Sprintf("%dm", q.MilliValue())
+}
+
+// formatMemory prints memory in mebibytes like kubectl top
+func formatMemory(q resource.Quantity) string {
+	return fmt.Sprintf("%dMi", q.Value()/(1024*1024))
+}
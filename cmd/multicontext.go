@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// contextClient returns a clientset for the named context of the
+// KUBECONFIG with the overrides of configOverrides, a user logged in during
+// this invocation uses the token of the login
+func (o *NsOptions) contextClient(name string) (kubernetes.Interface, error) {
+	loader := clientcmd.NewNonInteractiveClientConfig(o.rawConfig, name, o.configOverrides(name), o.configFlags.ToRawKubeConfigLoader().ConfigAccess())
+	restConfig, err := loader.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	if o.usesLogin(name) {
+		restConfig.AuthProvider = nil
+		restConfig.BearerToken = o.loginToken
+	}
+	o.applyRateLimits(restConfig)
+	return newClientset(restConfig)
+}
+
+// configOverrides returns the overrides of the kubeconfig flags for the
+// named context like ToRawKubeConfigLoader does. The credentials, TLS and
+// impersonation flags apply to every context, --cluster, --server and
+// --tls-server-name only to the current one.
+func (o *NsOptions) configOverrides(name string) *clientcmd.ConfigOverrides {
+	f := o.configFlags
+	overrides := &clientcmd.ConfigOverrides{
+		AuthInfo: api.AuthInfo{
+			ClientCertificate: flagValue(f.CertFile, ""),
+			ClientKey:         flagValue(f.KeyFile, ""),
+			Token:             flagValue(f.BearerToken, ""),
+			Username:          flagValue(f.Username, ""),
+			Password:          flagValue(f.Password, ""),
+		},
+		ClusterInfo: api.Cluster{
+			CertificateAuthority:  flagValue(f.CAFile, ""),
+			InsecureSkipTLSVerify: f.Insecure != nil && *f.Insecure,
+		},
+		Context: api.Context{
+			AuthInfo: flagValue(f.AuthInfoName, ""),
+		},
+		Timeout: flagValue(f.Timeout, ""),
+	}
+	overrides.AuthInfo.Impersonate, overrides.AuthInfo.ImpersonateGroups = o.impersonation()
+	if name == o.contextName() {
+		overrides.Context.Cluster = flagValue(f.ClusterName, "")
+		overrides.ClusterInfo.Server = flagValue(f.APIServer, "")
+		overrides.ClusterInfo.TLSServerName = flagValue(f.TLSServerName, "")
+	}
+	return overrides
+}
+
+// selectedContexts returns the sorted names of all contexts matching the
+// context pattern
+func (o *NsOptions) selectedContexts() []string {
+	names := []string{}
+	for name := range o.rawConfig.Contexts {
+		if o.contextPattern != "" {
+			if ok, _ := path.Match(o.contextPattern, name); !ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// setAllContexts sets the user specified namespace in every selected
+// context where it exists, the KUBECONFIG is written once
+func (o *NsOptions) setAllContexts() error {
+	contexts := o.selectedContexts()
+	if len(contexts) == 0 {
+		return fmt.Errorf("no context matches \"%s\"", o.contextPattern)
+	}
+
+	newNS := o.userSpecifiedNamespace
+	if o.config.IsProtected(newNS) && !o.yes && !o.dryRun {
+		ok, err := o.confirm(fmt.Sprintf("namespace \"%s\" is protected, switch anyway?", newNS))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	changed := map[string]string{}
+	failed := 0
+	for _, name := range contexts {
+		ctx := o.rawConfig.Contexts[name]
+		if ctx.Namespace == newNS {
+			fmt.Fprintf(o.Out, "context \"%s\": namespace already set to \"%s\"\n", name, newNS)
+			continue
+		}
+
+		if !o.force {
+			if err := o.validateContextNamespace(name, newNS); err != nil {
+				fmt.Fprintf(o.ErrOut, "context \"%s\": skipped, %v\n", name, err)
+				failed++
+				continue
+			}
+		}
+
+		previous := ctx.Namespace
+		if previous == "" {
+			previous = "default"
+		}
+		event := switchEvent{FromContext: name, ToContext: name, FromNamespace: previous, ToNamespace: newNS}
+		if o.dryRun {
+			changed[name] = previous
+			ctx.Namespace = newNS
+			continue
+		}
+		if err := o.preSwitch(event, o.ErrOut); err != nil {
+			fmt.Fprintf(o.ErrOut, "context \"%s\": skipped, %v\n", name, err)
+			failed++
+			continue
+		}
+		changed[name] = previous
+		ctx.Namespace = newNS
+	}
+
+	if len(changed) > 0 {
+		if err := o.modifyKubeconfig(o.rawConfig); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range contexts {
+		previous, ok := changed[name]
+		if !ok || o.dryRun {
+			continue
+		}
+		fmt.Fprintf(o.Out, "context \"%s\": namespace set to \"%s\"\n", name, newNS)
+		if err := o.rememberSwitch(name, previous, newNS); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to save previous namespace: %v\n", err)
+		}
+		if err := o.recordHistory(name, previous, newNS, ""); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to record history: %v\n", err)
+		}
+		o.postSwitch(switchEvent{FromContext: name, ToContext: name, FromNamespace: previous, ToNamespace: newNS}, o.ErrOut)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("namespace \"%s\" not set in %d of %d contexts", newNS, failed, len(contexts))
+	}
+	return nil
+}
+
+// validateContextNamespace ensures that namespace exists in the cluster of
+// the named context. If the lookup is forbidden the namespace is accepted
+// as it may still be usable.
+func (o *NsOptions) validateContextNamespace(name, namespace string) error {
+	clientset, err := o.contextClient(name)
+	if err != nil {
+		return err
+	}
+
+	err = o.withRetry(func() error {
+		_, err := clientset.CoreV1().Namespaces().Get(o.ctx, namespace, metav1.GetOptions{})
+		return err
+	})
+	switch {
+	case err == nil, apierrors.IsForbidden(err):
+		return nil
+	case apierrors.IsNotFound(err):
+		return fmt.Errorf("namespace \"%s\" does not exist", namespace)
+	}
+	return fmt.Errorf("failed to get namespace: %w", err)
+}
+
+// clusterListing is the result of listing the namespaces of one cluster
+type clusterListing struct {
+	cluster    string
+	context    string
+	namespaces map[string]bool
+	err        error
+}
+
+// clusterContexts returns one selected context per cluster sorted by the
+// cluster name, the first context in alphabetical order represents its
+// cluster
+func (o *NsOptions) clusterContexts() []clusterListing {
+	seen := map[string]bool{}
+	listings := []clusterListing{}
+	for _, name := range o.selectedContexts() {
+		cluster := o.rawConfig.Contexts[name].Cluster
+		if seen[cluster] {
+			continue
+		}
+		seen[cluster] = true
+		listings = append(listings, clusterListing{cluster: cluster, context: name})
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].cluster < listings[j].cluster
+	})
+	return listings
+}
+
+// listAllClusters lists the namespaces of the clusters concurrently and
+// prints a table with a column per cluster. Clusters which can't be listed
+// are reported and left out of the table.
+func (o *NsOptions) listAllClusters() error {
+	listings := o.clusterContexts()
+	if len(listings) == 0 {
+		return fmt.Errorf("no context matches \"%s\"", o.contextPattern)
+	}
+
+	// like the details of namespaces, at most enrichWorkers clusters are
+	// listed at once
+	sem := make(chan struct{}, enrichWorkers)
+	var wg sync.WaitGroup
+	for i := range listings {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(l *clusterListing) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			l.namespaces, l.err = o.listClusterNamespaces(l.context)
+		}(&listings[i])
+	}
+	wg.Wait()
+
+	names := map[string]bool{}
+	reachable := []clusterListing{}
+	for _, l := range listings {
+		if l.err != nil {
+			fmt.Fprintf(o.ErrOut, "cluster \"%s\" (context \"%s\"): %v\n", l.cluster, l.context, l.err)
+			continue
+		}
+		for name := range l.namespaces {
+			names[name] = true
+		}
+		reachable = append(reachable, l)
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	if o.sortOrder == config.SortDescending {
+		sort.Sort(sort.Reverse(sort.StringSlice(sorted)))
+	}
+
+	if err := o.printClusterTable(sorted, reachable); err != nil {
+		return err
+	}
+
+	if failed := len(listings) - len(reachable); failed > 0 {
+		return fmt.Errorf("failed to list namespaces in %d of %d clusters", failed, len(listings))
+	}
+	return nil
+}
+
+// listClusterNamespaces returns the names of the namespaces in the cluster
+// of the named context which are neither hidden nor filtered by the
+// namespace argument
+func (o *NsOptions) listClusterNamespaces(name string) (map[string]bool, error) {
+	clientset, err := o.contextClient(name)
+	if err != nil {
+		return nil, err
+	}
+	list, err := o.listChunks(o.namespaceAPI(clientset))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+
+	namespaces := map[string]bool{}
+	for _, ns := range list.Items {
+		if o.isHidden(ns.GetName()) || (o.userSpecifiedNamespace != "" && !o.matches(ns.GetName())) {
+			continue
+		}
+		namespaces[ns.GetName()] = true
+	}
+	return namespaces, nil
+}
+
+// printClusterTable prints the namespaces with a column per cluster marking
+// where they exist, with -o name only the namespaces are printed
+func (o *NsOptions) printClusterTable(names []string, listings []clusterListing) error {
+	if o.output == outputName {
+		for _, name := range names {
+			fmt.Fprintln(o.Out, name)
+		}
+		return nil
+	}
+
+	header := []string{"NAMESPACE"}
+	for _, l := range listings {
+		header = append(header, l.cluster)
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, name := range names {
+		row := []string{name}
+		for _, l := range listings {
+			if l.namespaces[name] {
+				row = append(row, "*")
+			} else {
+				row = append(row, "-")
+			}
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
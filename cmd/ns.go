@@ -3,50 +3,275 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	"github.com/postfinance/kubectl-ns/pkg/history"
+	"github.com/postfinance/kubectl-ns/pkg/state"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
 	// needed in order to support all authentication methods
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
+const (
+	// defaultCacheTTL is the maximum age of the cached namespace list
+	defaultCacheTTL = time.Minute
+	// defaultChunkSize is the number of namespaces requested at once, the
+	// same default as kubectl uses
+	defaultChunkSize = 500
+)
+
 var (
 	nsExample = `
-	# view the current namespace from your KUBECONFIG alongside all available namespaces
+	# view the current namespace from your KUBECONFIG alongside all available namespaces,
+	# or pick one interactively when running in a terminal
 	kubectl ns
 
 	# switch the namespace to foo if foo selects exactly one namespace, otherwise print a filtered list
-	kubectl ns foo`
+	kubectl ns foo
+
+	# list only namespaces of the payments team
+	kubectl ns -l team=payments
+
+	# wait for the namespace of a CI job to show up
+	kubectl ns -w ci-
+
+	# switch to a namespace which does not exist yet
+	kubectl ns foo --force
+
+	# create the namespace foo if it does not exist and switch to it
+	kubectl ns foo --create --labels team=payments
+
+	# switch to the only namespace matching a shell pattern or a regular expression
+	kubectl ns 'team-a-*'
+	kubectl ns --regex 'feature-\d+$'
+
+	# switch to the only namespace starting with payments-
+	kubectl ns --prefix payments-
+
+	# switch to the best fuzzy match, e.g. payments
+	kubectl ns --fuzzy pymt
+
+	# list the namespaces with their index and switch to the third one
+	kubectl ns --numbered
+	kubectl ns %3
+
+	# list the most recently used namespaces first
+	kubectl ns --sort-by last-used --sort-order desc
+
+	# only list the namespaces of team a, except the canary ones
+	kubectl ns --include 'team-a-*' --exclude '*-canary'
+
+	# quickly check for some preview namespaces in a huge cluster
+	kubectl ns --limit 10 preview-
+
+	# include system namespaces like kube-system in the list
+	kubectl ns --show-system
+
+	# set the namespace staging in all production contexts where it exists
+	kubectl ns staging --all-contexts --context-pattern 'prod-*'
+
+	# switch back to the default namespace configured for the context
+	kubectl ns --default
+
+	# group the namespaces by their Capsule tenant
+	kubectl ns --group-by tenant
+
+	# pick one of the namespaces of the Capsule tenant oil
+	kubectl ns --tenant oil
+
+	# pick one of the namespaces of the Rancher project p-5xqzv
+	kubectl ns --project p-5xqzv
+
+	# list the namespaces hosting a vcluster
+	kubectl ns --vclusters
+
+	# list the namespaces with Helm releases
+	kubectl ns --helm
+
+	# switch to the namespace pinned by a .kubens file of the project
+	kubectl ns --auto
+
+	# switch to the namespace of the current git branch, e.g. preview-x for feature/x
+	kubectl ns --from-branch
+
+	# switch to the namespace foo for 30 minutes
+	kubectl ns foo --for 30m
+
+	# switch to the namespace foo until the started shell exits
+	kubectl ns foo --until-exit
+
+	# run a command against the namespace foo without switching to it
+	kubectl ns exec foo -- kubectl get pods
+
+	# show the namespace hierarchy of the Hierarchical Namespace Controller
+	kubectl ns --tree
+
+	# show which namespaces exist in the clusters of all contexts
+	kubectl ns --all-clusters
+
+	# switch to the context other and its namespace foo at once
+	kubectl ns other:foo
+
+	# change the namespace of the context other without switching to it
+	kubectl ns foo --context other
+
+	# print only the current namespace
+	kubectl ns --current
+
+	# switch back to the previous namespace
+	kubectl ns -
+
+	# show the changes of the kubeconfig without writing them
+	kubectl ns --dry-run other:foo
+
+	# revert the last namespace switch, including a context change
+	kubectl ns undo
+
+	# roll the kubeconfig back to the most recent backup
+	kubectl ns restore
+
+	# list who switched to a production namespace during the last day
+	kubectl ns audit --namespace-pattern 'prod-*' --since 24h`
 )
 
+// ClientFactory creates the client used to access the API server, it allows
+// to replace the client with a fake implementation
+type ClientFactory func() (kubernetes.Interface, error)
+
 // NsOptions provides information required to update the current context
 // on a user's KUBECONFIG
 type NsOptions struct {
 	configFlags *genericclioptions.ConfigFlags
 	rawConfig   api.Config
+	config      *config.Config
 	args        []string
 
 	userSpecifiedNamespace string
+	alias                  string
 	namespaces             *v1.NamespaceList
+	output                 string
+	labelSelector          string
+	fieldSelector          string
+	watch                  bool
+	force                  bool
+	cacheTTL               time.Duration
+	chunkSize              int64
+	streamed               bool
+	limit                  int64
+	useDefault             bool
+	allContexts            bool
+	allClusters            bool
+	openshift              *bool
+	tree                   bool
+	groupBy                string
+	tenant                 string
+	project                string
+	vclusters              bool
+	hostedVClusters        map[string][]string
+	helm                   bool
+	namespaceReleases      map[string][]string
+	revertAfter            time.Duration
+	untilExit              bool
+	auto                   bool
+	pinned                 bool
+	fromBranch             bool
+	switchContext          string
+	contextPattern         string
+	retries                int
+	qps                    float32
+	burst                  int
+	retryBackoff           time.Duration
+	prefix                 bool
+	truncated              bool
+	listForbidden          bool
+	getForbidden           bool
+	refresh                bool
+	offline                bool
+	create                 bool
+	labels                 map[string]string
+	annotations            map[string]string
+	quotaTemplate          string
+	limitsTemplate         string
+	quota                  *v1.ResourceQuota
+	limitRange             *v1.LimitRange
+	template               string
+	manifests              []unstructured.Unstructured
+	createTimeout          time.Duration
+	waitServiceAccount     bool
+	yes                    bool
+	color                  bool
+	noColor                bool
+	sortOrder              string
+	sortBy                 string
+	include                []string
+	exclude                []string
+	interactive            bool
+	hideSystem             bool
+	showSystem             bool
+	regex                  bool
+	numbered               bool
+	counts                 bool
+	metrics                bool
+	terminating            bool
+	showLabels             bool
+	showAnnotations        []string
+	allowTerminating       bool
+	fuzzy                  bool
+	pattern                *regexp.Regexp
+	current                bool
+	writeTo                string
+	dryRun                 bool
+	inCluster              bool
+	backedUp               bool
+	// kubeconfigs replace the kubeconfig files of the flags while a
+	// pending revert is applied
+	kubeconfigs []string
+	// lookedUp is the namespace found by lookupNamespace
+	lookedUp *v1.Namespace
+	// listCached is set if o.namespaces was read from the cache
+	listCached bool
+	// loginToken is the id token of an oidc login during this invocation
+	loginToken string
+
+	newClient ClientFactory
+	// ctx is cancelled on interrupts, all API requests use it
+	ctx       context.Context
+	clientset kubernetes.Interface
 
 	genericclioptions.IOStreams
 }
 
 // NewNsOptions provides an instance of NsOptions with default values
 func NewNsOptions(streams genericclioptions.IOStreams) *NsOptions {
-	return &NsOptions{
+	o := &NsOptions{
 		configFlags: genericclioptions.NewConfigFlags(true),
+		ctx:         context.Background(),
 		IOStreams:   streams,
 	}
+	o.newClient = o.restClient
+
+	return o
 }
 
 // NewNsCmd provides a cobra command wrapping NsOptions
@@ -57,7 +282,16 @@ func NewNsCmd(streams genericclioptions.IOStreams) *cobra.Command {
 		Use:          "ns [new-namespace]",
 		Short:        "Display/Switch current namespace",
 		Example:      nsExample,
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
+		// errors are printed with hints by ExplainError
+		SilenceErrors: true,
+		ValidArgsFunction: func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return opt.completeNamespaces(args, toComplete)
+		},
+		PersistentPreRun: func(c *cobra.Command, args []string) {
+			opt.ctx = c.Context()
+		},
 		RunE: func(c *cobra.Command, args []string) error {
 			if err := opt.Complete(c, args); err != nil {
 				return err
@@ -67,13 +301,109 @@ func NewNsCmd(streams genericclioptions.IOStreams) *cobra.Command {
 				return err
 			}
 
-			if err := opt.Run(); err != nil {
+			if err := opt.withLogin(opt.Run); err != nil {
 				return err
 			}
 
 			return nil
 		},
 	}
+	cmd.Flags().StringVarP(&opt.labelSelector, "selector", "l", "", "only consider namespaces matching the label selector (e.g. -l team=payments)")
+	cmd.Flags().StringVar(&opt.fieldSelector, "field-selector", "", "only consider namespaces matching the field selector (e.g. --field-selector status.phase=Active)")
+	cmd.Flags().BoolVarP(&opt.watch, "watch", "w", false, "after listing the namespaces, watch for changes")
+	cmd.Flags().BoolVarP(&opt.force, "force", "f", false, "set the namespace without checking its existence, no API server access is required")
+	cmd.Flags().DurationVar(&opt.cacheTTL, "cache-ttl", defaultCacheTTL, "maximum age of the cached namespace list, 0 disables the cache")
+	cmd.Flags().Int64Var(&opt.chunkSize, "chunk-size", defaultChunkSize, "list namespaces in chunks of this size, 0 requests all at once")
+	cmd.Flags().Int64Var(&opt.limit, "limit", 0, "stop listing after this number of matching namespaces, bypasses the cache")
+	cmd.Flags().BoolVar(&opt.refresh, "refresh", false, "ignore the cached namespace list and fetch it from the API server")
+	cmd.Flags().BoolVar(&opt.offline, "offline", false, "never contact the API server, use the cached namespace list even if it is outdated")
+	cmd.Flags().BoolVar(&opt.create, "create", false, "create the namespace if it does not exist before switching to it")
+	cmd.Flags().StringToStringVar(&opt.labels, "labels", nil, "labels of a namespace created with --create (e.g. --labels team=payments,env=dev)")
+	cmd.Flags().StringToStringVar(&opt.annotations, "annotations", nil, "annotations of a namespace created with --create (e.g. --annotations owner=team-payments)")
+	cmd.Flags().StringVar(&opt.quotaTemplate, "quota-template", "", "YAML file of a resource quota created in a namespace created with --create")
+	cmd.Flags().StringVar(&opt.limitsTemplate, "limits-template", "", "YAML file of a limit range created in a namespace created with --create")
+	cmd.Flags().StringVar(&opt.template, "template", "", "bootstrap a namespace created with --create with the template of the configuration")
+	cmd.Flags().DurationVar(&opt.createTimeout, "create-timeout", createTimeout, "maximum time to wait for a namespace created with --create to become ready")
+	cmd.Flags().BoolVar(&opt.waitServiceAccount, "wait-service-account", false, "wait for the default service account of a namespace created with --create before switching")
+	cmd.Flags().BoolVarP(&opt.yes, "yes", "y", false, "switch to protected namespaces without confirmation")
+	cmd.Flags().BoolVar(&opt.color, "color", true, "colorize the output even if NO_COLOR is set, by default colors are used if the output is a terminal")
+	cmd.Flags().BoolVar(&opt.noColor, "no-color", false, "never colorize the output")
+	cmd.Flags().StringVar(&opt.sortOrder, "sort-order", config.SortAscending, "sort order of the namespace list, one of: asc|desc")
+	cmd.Flags().StringVar(&opt.sortBy, "sort-by", config.SortByName, "sort key of the namespace list, one of: "+strings.Join(config.SortKeys, "|"))
+	cmd.Flags().StringSliceVar(&opt.include, "include", nil, "only show namespaces matching the shell patterns in listings (e.g. --include 'team-a-*')")
+	cmd.Flags().StringSliceVar(&opt.exclude, "exclude", nil, "hide namespaces matching the shell patterns in listings (e.g. --exclude '*-canary')")
+	cmd.Flags().BoolVar(&opt.hideSystem, "hide-system", true, "hide system namespaces (kube-* by default) in listings without namespace argument")
+	cmd.Flags().BoolVar(&opt.showSystem, "show-system", false, "show system namespaces in listings, same as --hide-system=false")
+	cmd.Flags().BoolVar(&opt.interactive, "interactive", true, "pick the namespace interactively if no argument is given and a terminal is used")
+	cmd.Flags().BoolVar(&opt.regex, "regex", false, "treat the namespace argument as regular expression (e.g. --regex 'feature-\\d+')")
+	cmd.Flags().BoolVar(&opt.prefix, "prefix", false, "match the namespace argument as prefix, listing stops after the last possible match")
+	cmd.Flags().BoolVar(&opt.fuzzy, "fuzzy", false, "switch to the best fuzzy match of the namespace argument (e.g. pymt for payments)")
+	cmd.Flags().BoolVar(&opt.counts, "counts", false, "add the number of deployments and services to -o wide, they are counted concurrently")
+	cmd.Flags().BoolVar(&opt.metrics, "metrics", false, "add the CPU and memory usage of the pods reported by metrics-server to -o wide")
+	cmd.Flags().BoolVar(&opt.showLabels, "show-labels", false, "add the labels of every namespace to the listing")
+	cmd.Flags().StringSliceVar(&opt.showAnnotations, "show-annotations", nil, "add the annotations with the keys as columns to the listing (e.g. --show-annotations owner,cost-center)")
+	cmd.Flags().BoolVar(&opt.terminating, "terminating", false, "only list namespaces in phase Terminating")
+	cmd.Flags().BoolVar(&opt.allowTerminating, "allow-terminating", false, "switch to a namespace even if it is terminating")
+	cmd.Flags().BoolVar(&opt.numbered, "numbered", false, "print the index of every namespace, use %N or N as argument to switch to entry N of the last listing")
+	cmd.Flags().BoolVar(&opt.auto, "auto", false, "switch to the namespace pinned by a .kubens or .kubectl-ns.yaml file in the current directory or its parents")
+	cmd.Flags().BoolVar(&opt.fromBranch, "from-branch", false, "switch to the namespace derived from the current git branch by the branch rules of the configuration")
+	cmd.Flags().BoolVar(&opt.useDefault, "default", false, "switch to the default namespace of the context configured in the configuration file")
+	cmd.Flags().BoolVar(&opt.allContexts, "all-contexts", false, "set the namespace in every context of the KUBECONFIG where it exists")
+	cmd.Flags().StringVar(&opt.groupBy, "group-by", "", fmt.Sprintf("group the namespace list, one of %s", strings.Join(groupKeys, ", ")))
+	cmd.Flags().StringVar(&opt.tenant, "tenant", "", "only consider the namespaces of the Capsule tenant in listings and the picker")
+	cmd.Flags().StringVar(&opt.project, "project", "", "only consider the namespaces of the Rancher project in listings and the picker")
+	cmd.Flags().DurationVar(&opt.revertAfter, "for", 0, "switch the namespace temporarily and revert to the previous one after the duration (e.g. 30m)")
+	cmd.Flags().BoolVar(&opt.untilExit, "until-exit", false, "switch the namespace, start a shell and revert to the previous namespace when it exits")
+	cmd.Flags().BoolVar(&opt.vclusters, "vclusters", false, "only consider namespaces hosting a vcluster")
+	cmd.Flags().BoolVar(&opt.helm, "helm", false, "only consider namespaces with Helm releases")
+	cmd.Flags().BoolVar(&opt.tree, "tree", false, "show the namespace hierarchy of the Hierarchical Namespace Controller")
+	cmd.Flags().BoolVar(&opt.allClusters, "all-clusters", false, "list the namespaces of the clusters of all contexts in the KUBECONFIG side by side")
+	cmd.Flags().StringVar(&opt.contextPattern, "context-pattern", "", "only consider contexts matching the shell pattern with --all-contexts or --all-clusters (e.g. 'prod-*')")
+	cmd.Flags().BoolVar(&opt.current, "current", false, "print only the current namespace without accessing the API server")
+	cmd.PersistentFlags().IntVar(&opt.retries, "retries", defaultRetries, "number of retries of namespace requests failing with transient errors")
+	cmd.PersistentFlags().DurationVar(&opt.retryBackoff, "retry-backoff", defaultRetryBackoff, "delay before the first retry, it doubles with every further retry")
+	cmd.PersistentFlags().Float32Var(&opt.qps, "qps", 0, "maximum number of requests per second to the API server, 0 uses the client default of 5")
+	cmd.PersistentFlags().IntVar(&opt.burst, "burst", 0, "maximum burst of requests to the API server, 0 uses the client default of 10")
+	cmd.PersistentFlags().BoolVar(&opt.dryRun, "dry-run", false, "print the changes of the kubeconfig, the state and the cluster without applying them")
+	cmd.PersistentFlags().StringVar(&opt.writeTo, "write-to", "", "kubeconfig file the change is written to, by default the file defining the context")
+	// the namespace is the argument, a --namespace flag would be ambiguous
+	opt.configFlags.Namespace = nil
+	opt.configFlags.AddFlags(cmd.PersistentFlags())
+	cmd.PersistentFlags().Lookup("context").Usage = "the kubeconfig context to use, its namespace is changed without switching the current context"
+	cmd.PersistentFlags().Lookup("request-timeout").Usage = "the length of time to wait before giving up on a single server request (e.g. 5s), 0 waits forever"
+	cmd.Flags().StringVarP(&opt.output, "output", "o", "", "output format of the namespace list, one of: "+strings.Join(outputFormats, "|"))
+
+	cmd.AddCommand(NewHistoryCmd(opt))
+	cmd.AddCommand(NewCompletionCmd(streams))
+	cmd.AddCommand(NewCreateCmd(opt))
+	cmd.AddCommand(NewDeleteCmd(opt))
+	cmd.AddCommand(NewWaitCmd(opt))
+	cmd.AddCommand(NewDescribeCmd(opt))
+	cmd.AddCommand(NewFavCmd(opt))
+	cmd.AddCommand(NewPromptCmd(opt))
+	cmd.AddCommand(NewContextsCmd(opt))
+	cmd.AddCommand(NewExecCmd(opt))
+	cmd.AddCommand(NewRevertCmd(opt))
+	cmd.AddCommand(NewPinCmd(opt))
+	cmd.AddCommand(NewHookCmd(opt))
+	cmd.AddCommand(NewAuditCmd(opt))
+	cmd.AddCommand(NewUndoCmd(opt))
+	cmd.AddCommand(NewRestoreCmd(opt))
+	cmd.AddCommand(NewVersionCmd(opt))
+	cmd.AddCommand(NewUpgradeCmd(opt))
+	cmd.AddCommand(NewDoctorCmd(opt))
+	cmd.AddCommand(NewCanICmd(opt))
+	cmd.AddCommand(NewRBACCmd(opt))
+	cmd.AddCommand(NewQuotaCmd(opt))
+	cmd.AddCommand(NewEventsCmd(opt))
+	cmd.AddCommand(NewTopCmd(opt))
+	cmd.AddCommand(NewStuckCmd(opt))
+	cmd.AddCommand(NewLabelCmd(opt))
+	cmd.AddCommand(NewAnnotateCmd(opt))
+	cmd.AddCommand(NewCopyCmd(opt))
+	cmd.AddCommand(NewDiffCmd(opt))
+	cmd.AddCommand(NewExportCmd(opt))
+
 	return cmd
 }
 
@@ -81,30 +411,394 @@ func NewNsCmd(streams genericclioptions.IOStreams) *cobra.Command {
 func (o *NsOptions) Complete(cmd *cobra.Command, args []string) error {
 	o.args = args
 
+	// reverts of temporary switches are applied here as well, in case the
+	// background process was stopped before
+	if err := o.applyDueReverts(o.ErrOut); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to revert a temporary namespace switch: %v\n", err)
+	}
+
+	if err := o.loadConfig(); err != nil {
+		return err
+	}
+	o.applyConfig(cmd)
+
+	return nil
+}
+
+// applyConfig uses the settings of the configuration file for all flags
+// which are not set explicitly
+func (o *NsOptions) applyConfig(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	switch {
+	case o.noColor:
+		color.NoColor = true
+	case flags.Changed("color"):
+		color.NoColor = !o.color
+	case os.Getenv("NO_COLOR") != "":
+		color.NoColor = true
+	case o.config.Color != nil:
+		color.NoColor = !*o.config.Color
+	default:
+		color.NoColor = !isTerminal(o.Out)
+	}
+
+	if !flags.Changed("sort-order") && o.config.SortOrder != "" {
+		o.sortOrder = o.config.SortOrder
+	}
+	if !flags.Changed("sort-by") && o.config.SortBy != "" {
+		o.sortBy = o.config.SortBy
+	}
+	if !flags.Changed("include") {
+		o.include = o.config.Include
+	}
+	if !flags.Changed("exclude") {
+		o.exclude = o.config.Exclude
+	}
+	switch {
+	case flags.Changed("show-system"):
+		o.hideSystem = !o.showSystem
+	case !flags.Changed("hide-system") && o.config.HideSystem != nil:
+		o.hideSystem = *o.config.HideSystem
+	}
+	if !flags.Changed("retries") && o.config.Retries != nil {
+		o.retries = *o.config.Retries
+	}
+	if !flags.Changed("retry-backoff") && o.config.RetryBackoff != nil {
+		o.retryBackoff = o.config.RetryBackoff.Duration
+	}
+	if !flags.Changed("fuzzy") && o.config.Fuzzy != nil {
+		o.fuzzy = *o.config.Fuzzy
+	}
+	if !flags.Changed("interactive") && o.config.Interactive != nil {
+		o.interactive = *o.config.Interactive
+	}
+}
+
+// loadConfig reads the KUBECONFIG and the plugin configuration, no API
+// server access happens here
+func (o *NsOptions) loadConfig() error {
 	var err error
-	o.rawConfig, err = o.configFlags.ToRawKubeConfigLoader().RawConfig()
-	if err != nil {
+	if o.config, err = config.LoadDefault(); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if o.rawConfig, err = o.configFlags.ToRawKubeConfigLoader().RawConfig(); err != nil {
 		return err
 	}
+	o.detectInCluster()
 
-	restConfig, err := o.configFlags.ToRESTConfig()
+	return nil
+}
+
+// client returns the client for the API server, it is created on first use
+func (o *NsOptions) client() (kubernetes.Interface, error) {
+	if o.clientset == nil {
+		clientset, err := o.newClient()
+		if err != nil {
+			return nil, err
+		}
+		o.clientset = clientset
+	}
+	return o.clientset, nil
+}
+
+// restClient is the default ClientFactory building a clientset from the
+// KUBECONFIG and flags
+func (o *NsOptions) restClient() (kubernetes.Interface, error) {
+	restConfig, err := o.restConfig()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return newClientset(restConfig)
+}
+
+// newClientset returns a clientset preferring protobuf responses, they are
+// smaller and faster to decode than JSON. The API server falls back to JSON
+// if it can't encode a resource as protobuf. Request bodies stay JSON as
+// every server accepts them.
+func newClientset(restConfig *rest.Config) (kubernetes.Interface, error) {
+	restConfig.AcceptContentTypes = strings.Join([]string{runtime.ContentTypeProtobuf, runtime.ContentTypeJSON}, ",")
+	return kubernetes.NewForConfig(restConfig)
+}
+
+// restConfig returns the client configuration of the current context with
+// the client side rate limits applied
+func (o *NsOptions) restConfig() (*rest.Config, error) {
+	toRESTConfig := o.configFlags.ToRESTConfig
+	if o.inCluster {
+		toRESTConfig = o.inClusterRESTConfig
+	}
+	restConfig, err := toRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	if o.loginToken != "" {
+		// the oidc auth provider caches the expired session, so the token
+		// of the new login is used directly
+		restConfig.AuthProvider = nil
+		restConfig.BearerToken = o.loginToken
+	}
+	o.applyRateLimits(restConfig)
+	return restConfig, nil
+}
+
+// applyRateLimits sets the client side rate limits of restConfig
+func (o *NsOptions) applyRateLimits(restConfig *rest.Config) {
+	if o.output == outputWide {
+		restConfig.QPS, restConfig.Burst = enrichQPS, enrichBurst
+	}
+	if o.qps > 0 {
+		restConfig.QPS = o.qps
+	}
+	if o.burst > 0 {
+		restConfig.Burst = o.burst
+	}
+}
+
+// listNamespaces fetches all namespaces matching the selectors, the
+// namespace cache is used if no selectors are set
+func (o *NsOptions) listNamespaces() error {
+	cacheable := o.labelSelector == "" && o.fieldSelector == "" && !o.watch && o.limit == 0 && o.cacheTTL > 0
+
+	var c *cache.Cache
+	if cacheable {
+		var err error
+		if c, err = cache.New(o.cacheTTL); err != nil {
+			return err
+		}
+		if !o.refresh {
+			namespaces, ok, err := c.Get(o.cacheKey())
+			if err == nil && ok {
+				o.namespaces = &v1.NamespaceList{Items: namespaces}
+				o.listCached = true
+				return nil
+			}
+		}
 	}
-	clientset, err := kubernetes.NewForConfig(restConfig)
+
+	clientset, err := o.client()
 	if err != nil {
 		return err
 	}
 
-	namespaces, err := clientset.CoreV1().Namespaces().List(context.Background(), metav1.ListOptions{})
+	var namespaces *v1.NamespaceList
+	if o.isOpenShift(clientset) {
+		namespaces, err = o.listProjects()
+	} else if err = o.preflightList(); err == nil {
+		namespaces, err = o.listChunks(o.namespaceAPI(clientset))
+	}
+	switch {
+	case apierrors.IsForbidden(err):
+		namespaces, err = o.fallbackNamespaces(err)
+	// with --prefix the list stops at the last match, a cached partial
+	// list would hide the other namespaces from later listings
+	case err == nil && cacheable && !o.prefix:
+		if err := c.Set(o.cacheKey(), namespaces.Items); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to update namespace cache: %v\n", err)
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get namespaces: %w", err)
 	}
 	o.namespaces = namespaces
 
+	if o.truncated {
+		fmt.Fprintf(o.ErrOut, "warning: only the first %d matching namespaces are considered\n", o.limit)
+	}
+
 	return nil
 }
 
+// namespaceLister lists a chunk of namespaces, the namespace and the
+// OpenShift project API both list them ordered by name
+type namespaceLister func(opts metav1.ListOptions) (*v1.NamespaceList, error)
+
+// namespaceAPI lists the namespaces with the namespace API of clientset
+func (o *NsOptions) namespaceAPI(clientset kubernetes.Interface) namespaceLister {
+	return func(opts metav1.ListOptions) (*v1.NamespaceList, error) {
+		return clientset.CoreV1().Namespaces().List(o.ctx, opts)
+	}
+}
+
+// listChunks lists the namespaces in chunks of at most chunkSize items. If
+// names are streamed, every chunk is printed as soon as it arrives. With a
+// limit, listing stops as soon as enough matching namespaces are found. If
+// the continue token expires, the list is restarted after the last listed
+// namespace.
+func (o *NsOptions) listChunks(list namespaceLister) (*v1.NamespaceList, error) {
+	opts := metav1.ListOptions{
+		LabelSelector: o.labelSelector,
+		FieldSelector: o.fieldSelector,
+		Limit:         o.chunkSize,
+	}
+	if o.limit > 0 && o.userSpecifiedNamespace == "" && (opts.Limit == 0 || o.limit < opts.Limit) {
+		opts.Limit = o.limit
+	}
+
+	namespaces := &v1.NamespaceList{}
+	matches := int64(0)
+	last, restartAfter := "", ""
+	for {
+		var chunk *v1.NamespaceList
+		err := o.withRetry(func() (err error) {
+			chunk, err = list(opts)
+			return err
+		})
+		if (apierrors.IsResourceExpired(err) || apierrors.IsGone(err)) && opts.Continue != "" {
+			// names are ordered, so the already listed ones are skipped
+			fmt.Fprintf(o.ErrOut, "warning: the namespace list expired while listing in chunks, namespaces changed meanwhile may be missing or outdated\n")
+			opts.Continue, restartAfter = "", last
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		namespaces.ResourceVersion = chunk.ResourceVersion
+
+		for i, ns := range chunk.Items {
+			if restartAfter != "" && ns.GetName() <= restartAfter {
+				continue
+			}
+			if o.limit > 0 && matches == o.limit {
+				o.truncated = true
+				return namespaces, nil
+			}
+			if o.pastPrefix(ns.GetName()) {
+				return namespaces, nil
+			}
+			namespaces.Items = append(namespaces.Items, chunk.Items[i])
+			last = ns.GetName()
+
+			if o.isHidden(ns.GetName()) || (o.terminating && ns.Status.Phase != v1.NamespaceTerminating) ||
+				(o.userSpecifiedNamespace != "" && !o.matches(ns.GetName())) {
+				continue
+			}
+			matches++
+			if o.streamNames() {
+				o.streamed = true
+				fmt.Fprintf(o.Out, "%s\n", ns.GetName())
+			}
+		}
+
+		if chunk.Continue == "" {
+			return namespaces, nil
+		}
+		if o.limit > 0 && matches == o.limit {
+			o.truncated = true
+			return namespaces, nil
+		}
+		opts.Continue = chunk.Continue
+	}
+}
+
+// streamNames reports whether names can be printed while listing, this is
+// only possible if the order of the API server is kept
+func (o *NsOptions) streamNames() bool {
+	return o.output == outputName && o.userSpecifiedNamespace == "" && !o.watch && !o.allClusters && o.tenant == "" && o.project == "" && !o.vclusters && !o.helm &&
+		o.sortBy == config.SortByName && o.sortOrder == config.SortAscending
+}
+
+// lookupNamespace checks the existence of the user specified namespace with a
+// single request, found is false if the namespace has to be searched in the
+// list of all namespaces instead
+func (o *NsOptions) lookupNamespace() (found bool, err error) {
+	clientset, err := o.client()
+	if err != nil {
+		return false, err
+	}
+
+	// selectors can only be applied by listing, a field selector on the
+	// name keeps the response small
+	if o.labelSelector != "" || o.fieldSelector != "" {
+		selector := fields.OneTermEqualSelector("metadata.name", o.userSpecifiedNamespace)
+		if o.fieldSelector != "" {
+			userSelector, err := fields.ParseSelector(o.fieldSelector)
+			if err != nil {
+				return false, fmt.Errorf("invalid field selector: %w", err)
+			}
+			selector = fields.AndSelectors(selector, userSelector)
+		}
+		var namespaces *v1.NamespaceList
+		err := o.withRetry(func() (err error) {
+			namespaces, err = clientset.CoreV1().Namespaces().List(o.ctx, metav1.ListOptions{
+				LabelSelector: o.labelSelector,
+				FieldSelector: selector.String(),
+			})
+			return err
+		})
+		switch {
+		case err == nil && len(namespaces.Items) > 0:
+			o.lookedUp = &namespaces.Items[0]
+			return true, nil
+		case err == nil:
+			return false, nil
+		case apierrors.IsForbidden(err):
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace: %w", err)
+	}
+
+	err = o.withRetry(func() (err error) {
+		o.lookedUp, err = clientset.CoreV1().Namespaces().Get(o.ctx, o.userSpecifiedNamespace, metav1.GetOptions{})
+		return err
+	})
+	switch {
+	case err == nil:
+		return true, nil
+	case apierrors.IsForbidden(err):
+		o.getForbidden = true
+		return false, nil
+	case apierrors.IsNotFound(err):
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to get namespace: %w", err)
+}
+
+// fallbackNamespaces is used if listing namespaces is forbidden, the
+// namespaces configured in KUBECONFIG contexts of the current cluster are
+// used instead.
+func (o *NsOptions) fallbackNamespaces(listErr error) (*v1.NamespaceList, error) {
+	o.listForbidden = true
+	fmt.Fprintf(o.ErrOut, "warning: %v\n", listErr)
+	fmt.Fprintf(o.ErrOut, "warning: %s\n", missingPermission("list", ""))
+	fmt.Fprintf(o.ErrOut, "warning: only namespaces configured in KUBECONFIG contexts of the current cluster are shown\n")
+
+	return &v1.NamespaceList{Items: o.kubeconfigNamespaces()}, nil
+}
+
+// kubeconfigNamespaces returns the namespaces of all contexts in KUBECONFIG
+// which use the same cluster as the current context
+func (o *NsOptions) kubeconfigNamespaces() []v1.Namespace {
+	namespaces := []v1.Namespace{}
+
+	current, ok := o.rawConfig.Contexts[o.contextName()]
+	if !ok {
+		return namespaces
+	}
+
+	seen := map[string]bool{}
+	for _, ctx := range o.rawConfig.Contexts {
+		name := ctx.Namespace
+		if name == "" {
+			name = "default"
+		}
+		if ctx.Cluster != current.Cluster || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		ns := v1.Namespace{}
+		ns.SetName(name)
+		namespaces = append(namespaces, ns)
+	}
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].GetName() < namespaces[j].GetName()
+	})
+
+	return namespaces
+}
+
 // Validate ensures that all required arguments and flag values are provided
 func (o *NsOptions) Validate() error {
 	if len(o.args) > 1 {
@@ -115,79 +809,688 @@ func (o *NsOptions) Validate() error {
 		o.userSpecifiedNamespace = o.args[0]
 	}
 
-	return nil
+	if o.auto {
+		if len(o.args) > 0 || o.useDefault {
+			return fmt.Errorf("--auto accepts no namespace argument and can't be combined with --default")
+		}
+		// without project file there is nothing to do, Run returns early
+		found, err := o.projectNamespace()
+		if err != nil || !found {
+			return err
+		}
+		o.pinned = true
+	}
+
+	if o.fromBranch {
+		if len(o.args) > 0 || o.auto || o.useDefault {
+			return fmt.Errorf("--from-branch accepts no namespace argument and can't be combined with --auto or --default")
+		}
+		if err := o.branchNamespace(); err != nil {
+			return err
+		}
+	}
+
+	if err := o.splitContext(); err != nil {
+		return err
+	}
+
+	if err := o.checkContext(); err != nil {
+		return err
+	}
+
+	if o.useDefault {
+		if len(o.args) > 0 {
+			return fmt.Errorf("--default accepts no namespace argument")
+		}
+		ctx := o.rawConfig.Contexts[o.contextName()]
+		o.userSpecifiedNamespace = o.config.DefaultNamespace(o.contextName(), ctx.Cluster)
+	}
+
+	if err := validateOutput(o.output); err != nil {
+		return err
+	}
+
+	if err := config.ValidateSortOrder(o.sortOrder); err != nil {
+		return err
+	}
+
+	if err := config.ValidateSortBy(o.sortBy); err != nil {
+		return err
+	}
+
+	if o.current && (len(o.args) > 0 || (o.output != "" && o.output != outputName && o.output != outputJSON && o.output != outputYAML)) {
+		return fmt.Errorf("--current accepts no arguments and only supports the output formats name, json and yaml")
+	}
+
+	if o.allContexts && (len(o.args) == 0 || o.create || o.offline || o.watch || o.fuzzy || o.regex) {
+		return fmt.Errorf("--all-contexts requires a namespace argument and can't be combined with --create, --offline, --watch, --fuzzy or --regex")
+	}
+
+	// with an output format namespaces are only printed, never switched to
+	if o.output != "" && (o.force || o.create || o.allContexts || o.revertAfter > 0 || o.untilExit || o.auto) {
+		return fmt.Errorf("--output can't be combined with --force, --create, --all-contexts, --for, --until-exit or --auto")
+	}
+
+	if o.allContexts && *o.configFlags.Context != "" {
+		return fmt.Errorf("--all-contexts can't be combined with --context, use --context-pattern instead")
+	}
+
+	if o.allClusters && (o.allContexts || *o.configFlags.Context != "" || o.switchContext != "" || o.current || o.useDefault ||
+		o.create || o.force || o.offline || o.watch || o.limit > 0) {
+		return fmt.Errorf("--all-clusters can't be combined with --all-contexts, --context, context:namespace, --current, --default, --create, --force, --offline, --watch or --limit")
+	}
+
+	if o.allClusters && o.output != "" && o.output != outputName {
+		return fmt.Errorf("--all-clusters only supports the output format name")
+	}
+
+	if o.contextPattern != "" && !o.allContexts && !o.allClusters {
+		return fmt.Errorf("--context-pattern can only be used with --all-contexts or --all-clusters")
+	}
+
+	if o.chunkSize < 0 || o.limit < 0 || o.retries < 0 || o.qps < 0 || o.burst < 0 {
+		return fmt.Errorf("--chunk-size, --limit, --retries, --qps and --burst must not be negative")
+	}
+
+	if o.limit > 0 && (o.offline || o.watch) {
+		return fmt.Errorf("--limit can't be combined with --offline or --watch")
+	}
+
+	if o.revertAfter < 0 {
+		return fmt.Errorf("--for must not be negative")
+	}
+
+	if (o.revertAfter > 0 || o.untilExit) && (len(o.args) == 0 && !o.useDefault || o.revertAfter > 0 && o.untilExit ||
+		o.allContexts || o.allClusters || o.current || o.watch) {
+		return fmt.Errorf("--for and --until-exit require a namespace argument and can't be combined with each other, --all-contexts, --all-clusters, --current or --watch")
+	}
+
+	if o.tree && (len(o.args) > 0 || o.output != "" || o.numbered || o.watch) {
+		return fmt.Errorf("--tree accepts no arguments and can't be combined with --output, --numbered or --watch")
+	}
+
+	if err := validateGroupBy(o.groupBy); err != nil {
+		return err
+	}
+
+	if o.groupBy != "" && (o.output != "" || o.numbered || o.tree || o.watch) {
+		return fmt.Errorf("--group-by can't be combined with --output, --numbered, --tree or --watch")
+	}
+
+	if (o.tenant != "" || o.project != "" || o.vclusters || o.helm) && (o.watch || o.allClusters) {
+		return fmt.Errorf("--tenant, --project, --vclusters and --helm can't be combined with --watch or --all-clusters")
+	}
+
+	if (o.showLabels || len(o.showAnnotations) > 0) && (o.output != "" && o.output != outputWide || o.groupBy != "" || o.tree) {
+		return fmt.Errorf("--show-labels and --show-annotations can only be used with the plain listing or -o wide")
+	}
+
+	for _, key := range o.showAnnotations {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid annotation key \"%s\": %s", key, strings.Join(errs, "; "))
+		}
+	}
+
+	if o.numbered && o.output != "" {
+		return fmt.Errorf("--numbered can't be combined with --output")
+	}
+
+	if (o.counts || o.metrics) && o.output != outputWide {
+		return fmt.Errorf("--counts and --metrics require -o wide")
+	}
+
+	if o.terminating && (o.watch || o.tree || o.allClusters) {
+		return fmt.Errorf("--terminating can't be combined with --watch, --tree or --all-clusters")
+	}
+
+	// --default, --auto and --from-branch have resolved the namespace already
+	if o.force && o.userSpecifiedNamespace == "" {
+		return fmt.Errorf("--force requires a namespace argument or --default")
+	}
+
+	if o.offline && (o.watch || o.refresh) {
+		return fmt.Errorf("--offline can't be combined with --watch or --refresh")
+	}
+
+	if o.create && (o.userSpecifiedNamespace == "" || o.force || o.offline || o.watch) {
+		return fmt.Errorf("--create requires a namespace argument and can't be combined with --force, --offline or --watch")
+	}
+
+	if (len(o.labels) > 0 || len(o.annotations) > 0 || o.quotaTemplate != "" || o.limitsTemplate != "" || o.template != "" || o.waitServiceAccount) && !o.create {
+		return fmt.Errorf("--labels, --annotations, --quota-template, --limits-template, --template and --wait-service-account can only be used with --create")
+	}
+
+	if err := o.loadCreateTemplates(); err != nil {
+		return err
+	}
+
+	if o.createTimeout <= 0 {
+		return fmt.Errorf("--create-timeout must be positive")
+	}
+
+	if o.userSpecifiedNamespace == "-" {
+		previous, err := o.previousNs()
+		if err != nil {
+			return err
+		}
+		o.userSpecifiedNamespace = previous
+	}
+
+	if err := o.resolveIndex(); err != nil {
+		return err
+	}
+
+	if target, ok := o.config.Aliases[o.userSpecifiedNamespace]; ok {
+		o.alias = o.userSpecifiedNamespace
+		o.userSpecifiedNamespace = target
+	}
+
+	return o.compilePattern()
 }
 
 // Run lists all available namespaces, or updates the current namesapce
 // based on a provided namespace.
 func (o *NsOptions) Run() error {
-	selected := []string{}
+	if o.auto && (!o.pinned || o.pinnedIsCurrent()) {
+		return nil
+	}
+
+	if o.current {
+		return o.printCurrent()
+	}
+
+	if o.allContexts {
+		return o.setAllContexts()
+	}
+
+	if o.allClusters {
+		return o.listAllClusters()
+	}
+
+	if o.force {
+		return o.changeCurrentNs(o.userSpecifiedNamespace)
+	}
+
+	if o.create {
+		if err := o.createNamespace(); err != nil {
+			return err
+		}
+		return o.changeCurrentNs(o.userSpecifiedNamespace)
+	}
+
+	if o.offline {
+		found, err := o.loadOfflineNamespaces()
+		if err != nil {
+			return err
+		}
+		if !found {
+			if o.isPattern() {
+				return fmt.Errorf("can't match the pattern \"%s\" without cached namespaces", o.userSpecifiedNamespace)
+			}
+			return o.selectNamespace(o.userSpecifiedNamespace)
+		}
+	} else {
+		if o.userSpecifiedNamespace != "" && !o.watch && !o.isPattern() {
+			found, err := o.lookupNamespace()
+			if err != nil {
+				return err
+			}
+			if found {
+				return o.selectNamespace(o.userSpecifiedNamespace)
+			}
+		}
+
+		if err := o.listNamespaces(); err != nil {
+			return err
+		}
+	}
+
+	o.namespaces.Items = o.prepareNamespaces(o.namespaces.Items)
+
+	if o.tenant != "" {
+		namespaces, err := o.filterTenant(o.namespaces.Items)
+		if err != nil {
+			return err
+		}
+		o.namespaces.Items = namespaces
+	}
+	if o.project != "" {
+		o.namespaces.Items = o.filterProject(o.namespaces.Items)
+	}
+	if o.vclusters {
+		if err := o.filterVClusters(); err != nil {
+			return err
+		}
+	}
+	if o.helm {
+		if err := o.filterHelm(); err != nil {
+			return err
+		}
+	}
+
+	if o.watch {
+		return o.watchNamespaces()
+	}
+
+	if o.userSpecifiedNamespace == "" {
+		if o.streamed {
+			return nil
+		}
+		if o.tree {
+			return o.printTree(o.namespaces.Items)
+		}
+		if o.output == "" && o.isInteractive() {
+			return o.pickNamespace(namespaceNames(o.namespaces.Items))
+		}
+		return o.printNamespaces(o.namespaces.Items)
+	}
+
+	if name, ok := o.subnamespaceByShortName(o.namespaces.Items); ok && !o.isPattern() {
+		return o.selectNamespace(name)
+	}
+
+	// with a limit further matches may exist, so the result is only shown
+	if o.fuzzy && !o.truncated {
+		name, err := o.bestFuzzyMatch(o.namespaces.Items)
+		if err != nil {
+			return err
+		}
+		return o.selectNamespace(name)
+	}
+
+	selected := []v1.Namespace{}
 	for _, ns := range o.namespaces.Items {
-		if ns.GetName() == o.userSpecifiedNamespace {
-			selected = []string{ns.GetName()}
+		if ns.GetName() == o.userSpecifiedNamespace && !o.isPattern() {
+			selected = []v1.Namespace{ns}
 			break
 		}
-		if strings.Contains(ns.GetName(), o.userSpecifiedNamespace) {
-			selected = append(selected, ns.GetName())
+		if o.matches(ns.GetName()) {
+			selected = append(selected, ns)
 		}
 	}
 	switch len(selected) {
 	case 0:
+		if o.isPattern() {
+			return fmt.Errorf("can't change namespace, no namespace matches \"%s\"", o.userSpecifiedNamespace)
+		}
+		if o.listForbidden && o.getForbidden {
+			return fmt.Errorf("can't change namespace, the existence of \"%s\" can't be checked: %s, use --force to set it without checking", o.userSpecifiedNamespace, missingPermission("get", o.userSpecifiedNamespace))
+		}
 		return fmt.Errorf("can't change namespace, \"%s\" does not exist", o.userSpecifiedNamespace)
 	case 1:
-		return o.changeCurrentNs(selected[0])
+		if !o.truncated && o.output == "" {
+			return o.changeCurrentNs(selected[0].GetName())
+		}
+	}
+	if o.output == "" && o.isInteractive() {
+		return o.pickNamespace(namespaceNames(selected))
 	}
 	return o.printNamespaces(selected)
 }
 
+// selectNamespace switches to the found namespace, with an output format it
+// is printed instead and the kubeconfig is left untouched
+func (o *NsOptions) selectNamespace(name string) error {
+	if o.output == "" {
+		return o.changeCurrentNs(name)
+	}
+	if o.lookedUp != nil && o.lookedUp.GetName() == name {
+		return o.printNamespaces([]v1.Namespace{*o.lookedUp})
+	}
+	if o.namespaces != nil {
+		for _, ns := range o.namespaces.Items {
+			if ns.GetName() == name {
+				return o.printNamespaces([]v1.Namespace{ns})
+			}
+		}
+	}
+	return fmt.Errorf("can't print namespace \"%s\", it is not cached", name)
+}
+
 func (o *NsOptions) changeCurrentNs(newNS string) error {
 	if err := o.checkContext(); err != nil {
 		return err
 	}
 
-	currentNs := o.rawConfig.Contexts[o.rawConfig.CurrentContext].Namespace
+	currentNs := o.rawConfig.Contexts[o.contextName()].Namespace
 
-	if currentNs != newNS {
+	if currentNs != newNS || o.switchContext != "" {
+		if !o.allowTerminating && o.isTerminating(newNS) {
+			return fmt.Errorf("can't change namespace, \"%s\" is terminating, use --allow-terminating to switch anyway", newNS)
+		}
+		if o.config.IsProtected(newNS) && !o.yes && !o.dryRun {
+			ok, err := o.confirm(fmt.Sprintf("namespace \"%s\" is protected, switch anyway?", newNS))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errAborted
+			}
+		}
+
+		event := switchEvent{
+			FromContext:   o.contextName(),
+			ToContext:     o.contextName(),
+			FromNamespace: currentNs,
+			ToNamespace:   newNS,
+		}
+		if o.switchContext != "" {
+			event.FromContext = o.rawConfig.CurrentContext
+			if ctx, ok := o.rawConfig.Contexts[event.FromContext]; ok {
+				event.FromNamespace = ctx.Namespace
+			}
+		}
+		if event.FromNamespace == "" {
+			event.FromNamespace = "default"
+		}
+		if !o.dryRun {
+			if err := o.preSwitch(event, o.ErrOut); err != nil {
+				return err
+			}
+		}
 
-		o.rawConfig.Contexts[o.rawConfig.CurrentContext].Namespace = newNS
-		if err := clientcmd.ModifyConfig(clientcmd.NewDefaultPathOptions(),
-			o.rawConfig, true); err != nil {
+		o.rawConfig.Contexts[o.contextName()].Namespace = newNS
+		if o.switchContext != "" {
+			o.rawConfig.CurrentContext = o.switchContext
+		}
+		if err := o.modifyKubeconfig(o.rawConfig); err != nil {
 			return err
 		}
+		if o.dryRun {
+			return nil
+		}
+
+		msg := fmt.Sprintf("namespace set to \"%s\"", newNS)
+		if o.alias != "" && newNS == o.config.Aliases[o.alias] {
+			msg += fmt.Sprintf(" (alias \"%s\")", o.alias)
+		}
+		switch {
+		case o.switchContext != "":
+			msg = fmt.Sprintf("context set to \"%s\", %s", o.switchContext, msg)
+		case *o.configFlags.Context != "":
+			msg += fmt.Sprintf(" in context \"%s\"", o.contextName())
+		}
+		if o.inCluster {
+			// the output is the shell variable of the namespace
+			fmt.Fprintln(o.ErrOut, msg)
+		} else {
+			fmt.Fprintln(o.Out, msg)
+		}
+
+		if currentNs == "" {
+			currentNs = "default"
+		}
+		if err := o.rememberSwitch(o.contextName(), currentNs, newNS); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to save previous namespace: %v\n", err)
+		}
+		previousContext := ""
+		if o.switchContext != "" && event.FromContext != o.switchContext {
+			previousContext = event.FromContext
+		}
+		if err := o.recordHistory(o.contextName(), currentNs, newNS, previousContext); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to record history: %v\n", err)
+		}
+		o.postSwitch(event, o.ErrOut)
+
+		if o.revertAfter > 0 || o.untilExit {
+			return o.temporarySwitch(currentNs, newNS)
+		}
+	}
+	return nil
+}
+
+// previousNs returns the namespace which was active in the current context
+// before the last switch
+func (o *NsOptions) previousNs() (string, error) {
+	s, err := o.loadState()
+	if err != nil {
+		return "", fmt.Errorf("failed to load state: %w", err)
+	}
+
+	previous, ok := s.Previous[o.contextName()]
+	if !ok {
+		return "", fmt.Errorf("no previous namespace found for context \"%s\"", o.contextName())
+	}
+	return previous, nil
+}
+
+// rememberSwitch saves the previous namespace of the context for switching
+// back and adds the new one to its recently used namespaces
+func (o *NsOptions) rememberSwitch(contextName, previous, current string) error {
+	return o.updateState(func(s *state.State) (bool, error) {
+		s.SetPrevious(contextName, previous)
+		s.AddRecent(contextName, current)
+		return true, nil
+	})
+}
 
-		fmt.Fprintf(o.Out, "namespace set to \"%s\"\n", newNS)
+// saveListing remembers the printed namespaces for switching by index
+func (o *NsOptions) saveListing(namespaces []string) error {
+	return state.UpdateDefault(func(s *state.State) (bool, error) {
+		return s.SetListing(o.contextName(), namespaces), nil
+	})
+}
+
+// resolveIndex replaces an argument %N with entry N of the last listing. A
+// plain number N is only resolved if the last listing has such an entry.
+func (o *NsOptions) resolveIndex() error {
+	arg := strings.TrimPrefix(o.userSpecifiedNamespace, "%")
+	index, err := strconv.Atoi(arg)
+	if err != nil || arg == "" || arg[0] == '+' || arg[0] == '-' {
+		return nil
+	}
+	explicit := arg != o.userSpecifiedNamespace
+
+	s, err := state.LoadDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	ns, ok := s.ListingEntry(o.contextName(), index)
+	switch {
+	case ok:
+		o.userSpecifiedNamespace = ns
+	case explicit:
+		return fmt.Errorf("no entry %d found in the last namespace listing of context \"%s\"", index, o.contextName())
 	}
 	return nil
 }
 
-func (o *NsOptions) printNamespaces(namespaces []string) error {
-	red := color.New(color.FgRed)
+// isInteractive reports whether the picker is enabled and both input and
+// output streams are attached to a terminal
+func (o *NsOptions) isInteractive() bool {
+	return o.interactive && isTerminal(o.In) && isTerminal(o.Out)
+}
 
+// pickNamespace lets the user select the new namespace interactively
+func (o *NsOptions) pickNamespace(namespaces []string) error {
 	if err := o.checkContext(); err != nil {
 		return err
 	}
-	currentNS := o.rawConfig.Contexts[o.rawConfig.CurrentContext].Namespace
+	currentNS := o.rawConfig.Contexts[o.contextName()].Namespace
 
-	current := false
-	for _, ns := range namespaces {
-		if ns == currentNS {
-			current = true // postpone printing the current namespace
-		} else {
-			fmt.Fprintf(o.Out, "%s\n", ns)
+	var ns string
+	recent, err := o.recentOrder(namespaces)
+	if err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to load history, using the sorted order: %v\n", err)
+		ns, err = pick(o.In.(*os.File), o.Out, namespaces, currentNS)
+	} else {
+		ns, err = pickRecent(o.In.(*os.File), o.Out, namespaces, recent, currentNS)
+	}
+	if err == errPickerAborted {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return o.changeCurrentNs(ns)
+}
+
+// recordHistory appends a switch of contextName from one namespace to
+// another, previousContext is the current context before the switch if it
+// was changed
+func (o *NsOptions) recordHistory(contextName, from, to, previousContext string) error {
+	store, err := history.NewDefaultStore()
+	if err != nil {
+		return err
+	}
+	return store.Append(history.Entry{
+		Time:            time.Now(),
+		Context:         contextName,
+		From:            from,
+		To:              to,
+		PreviousContext: previousContext,
+	})
+}
+
+func (o *NsOptions) printNamespaces(namespaces []v1.Namespace) error {
+	if err := o.checkContext(); err != nil {
+		return err
+	}
+	currentNS := o.rawConfig.Contexts[o.contextName()].Namespace
+
+	switch {
+	case o.output == outputJSON, o.output == outputYAML:
+		return o.printStructured(namespaces, currentNS)
+	case o.output == outputWide:
+		return o.printWide(namespaces, currentNS)
+	case o.output == outputName:
+		for _, ns := range namespaces {
+			fmt.Fprintf(o.Out, "%s\n", ns.GetName())
 		}
+		return nil
+	case strings.HasPrefix(o.output, outputCustomColumns):
+		return o.printCustomColumns(namespaces, strings.TrimPrefix(o.output, outputCustomColumns))
+	case strings.HasPrefix(o.output, outputGoTemplate):
+		return o.printGoTemplate(namespaces, strings.TrimPrefix(o.output, outputGoTemplate))
+	}
+
+	if o.groupBy != "" {
+		return o.printGrouped(namespaces, currentNS)
 	}
 
-	if current {
-		red.Fprintf(o.Out, "%s\n", currentNS)
+	// without colors on a pipe the current namespace is neither
+	// highlighted nor moved, which keeps the output easy to process
+	plain := color.NoColor && !isTerminal(o.Out)
+	if !o.offline {
+		o.markVClusters()
+	}
+
+	listing := make([]v1.Namespace, 0, len(namespaces))
+	var current *v1.Namespace
+	for i := range namespaces {
+		if !plain && namespaces[i].GetName() == currentNS {
+			current = &namespaces[i] // postpone printing the current namespace
+			continue
+		}
+		listing = append(listing, namespaces[i])
+	}
+	if current != nil {
+		listing = append(listing, *current)
+	}
+
+	header, columns := o.listingColumns(listing)
+	rows := make([][]string, len(listing))
+	for i, ns := range listing {
+		name := ns.GetName()
+		terminating := ns.Status.Phase == v1.NamespaceTerminating
+		switch {
+		case plain:
+		case terminating && name != currentNS:
+			name = color.New(color.Faint).Sprint(name)
+		default:
+			name = sprintStyled(o.namespaceStyle(ns, name == currentNS), name)
+		}
+		if vclusters, ok := o.hostedVClusters[ns.GetName()]; ok {
+			name += fmt.Sprintf(" (vcluster %s)", strings.Join(vclusters, ","))
+		}
+		if releases, ok := o.namespaceReleases[ns.GetName()]; ok {
+			name += fmt.Sprintf(" (helm %s)", strings.Join(releases, ","))
+		}
+		if terminating && !plain {
+			name += " (terminating)"
+		}
+		rows[i] = append([]string{name}, columns[i]...)
+	}
+
+	width := len(strconv.Itoa(len(listing)))
+	if len(header) > 0 {
+		widths := columnWidths(append([]string{"NAME"}, header...), rows)
+		if o.numbered {
+			fmt.Fprintf(o.Out, "%*s ", width, "")
+		}
+		fmt.Fprintln(o.Out, padColumns(append([]string{"NAME"}, header...), widths))
+		for i, row := range rows {
+			if o.numbered {
+				fmt.Fprintf(o.Out, "%*d ", width, i+1)
+			}
+			fmt.Fprintln(o.Out, padColumns(row, widths))
+		}
+	} else {
+		for i, row := range rows {
+			if o.numbered {
+				fmt.Fprintf(o.Out, "%*d ", width, i+1)
+			}
+			fmt.Fprintf(o.Out, "%s\n", row[0])
+		}
+	}
+
+	if err := o.saveListing(namespaceNames(listing)); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to save namespace listing: %v\n", err)
 	}
 
 	return nil
 }
 
 func (o *NsOptions) checkContext() error {
-	currentCtx := o.rawConfig.CurrentContext
+	currentCtx := o.contextName()
 	if _, ok := o.rawConfig.Contexts[currentCtx]; !ok {
+		if *o.configFlags.Context != "" {
+			return fmt.Errorf("context %s not found in KUBECONFIG", currentCtx)
+		}
 		return fmt.Errorf("current context %s not found anymore in KUBECONFIG", currentCtx)
 	}
 	return nil
 }
+
+// splitContext handles arguments like context:namespace which switch the
+// current context as well. Namespaces can't contain colons, so the argument
+// is split at the last one.
+func (o *NsOptions) splitContext() error {
+	i := strings.LastIndex(o.userSpecifiedNamespace, ":")
+	if i < 0 {
+		return nil
+	}
+	name, namespace := o.userSpecifiedNamespace[:i], o.userSpecifiedNamespace[i+1:]
+
+	if _, ok := o.rawConfig.Contexts[name]; !ok {
+		return fmt.Errorf("context %s not found in KUBECONFIG", name)
+	}
+	if namespace == "" {
+		return fmt.Errorf("namespace missing in \"%s\", use context:namespace", o.userSpecifiedNamespace)
+	}
+	if *o.configFlags.Context != "" || o.allContexts {
+		return fmt.Errorf("context:namespace can't be combined with --context or --all-contexts")
+	}
+	// a revert only restores the namespace, the current context would
+	// stay switched
+	if name != o.rawConfig.CurrentContext && (o.revertAfter > 0 || o.untilExit) {
+		return fmt.Errorf("context:namespace of another context can't be combined with --for or --until-exit")
+	}
+
+	o.userSpecifiedNamespace = namespace
+	if name != o.rawConfig.CurrentContext {
+		o.switchContext = name
+		*o.configFlags.Context = name
+	}
+	return nil
+}
+
+// contextName returns the context selected by --context, by default the
+// current context. Only the namespace of this context is changed, the
+// current context stays the same.
+func (o *NsOptions) contextName() string {
+	if *o.configFlags.Context != "" {
+		return *o.configFlags.Context
+	}
+	return o.rawConfig.CurrentContext
+}
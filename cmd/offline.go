@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+)
+
+// loadOfflineNamespaces uses the cached namespace list regardless of its
+// age. If nothing is cached for the current cluster found is false and a
+// provided namespace has to be used without validation.
+func (o *NsOptions) loadOfflineNamespaces() (found bool, err error) {
+	if err := o.checkContext(); err != nil {
+		return false, err
+	}
+
+	c, err := cache.New(o.cacheTTL)
+	if err != nil {
+		return false, err
+	}
+	entry, err := c.Load(o.cacheKey())
+	if err != nil {
+		return false, fmt.Errorf("failed to read namespace cache: %w", err)
+	}
+
+	if entry == nil {
+		if o.userSpecifiedNamespace == "" {
+			return false, fmt.Errorf("no cached namespaces found for context \"%s\", run kubectl ns once while online", o.contextName())
+		}
+		fmt.Fprintf(o.ErrOut, "warning: no cached namespaces found, \"%s\" is used without validation\n", o.userSpecifiedNamespace)
+		return false, nil
+	}
+
+	if c.Stale(entry) {
+		fmt.Fprintf(o.ErrOut, "warning: cached namespace list is %s old\n", duration.HumanDuration(time.Since(entry.Time)))
+	}
+	o.namespaces = &v1.NamespaceList{Items: entry.Namespaces}
+
+	return true, nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// annotationDisplayName and annotationDescription are set on OpenShift
+	// projects and their namespaces
+	annotationDisplayName = "openshift.io/display-name"
+	annotationDescription = "openshift.io/description"
+)
+
+// projectResource is the OpenShift project API, it lists the projects a
+// user has access to without cluster wide permissions on namespaces
+var projectResource = schema.GroupVersionResource{Group: "project.openshift.io", Version: "v1", Resource: "projects"}
+
+// discoveryTTL is the time the result of the discovery of the project API
+// is cached, clusters rarely gain or lose it
+const discoveryTTL = 10 * time.Minute
+
+// isOpenShift reports whether the API server serves the OpenShift project
+// API. The result is cached next to the namespace list unless the cache is
+// disabled or --refresh is set. If the discovery fails the namespaces are
+// listed with a warning.
+func (o *NsOptions) isOpenShift(clientset kubernetes.Interface) bool {
+	if o.openshift != nil {
+		return *o.openshift
+	}
+
+	var c *cache.Cache
+	if o.cacheTTL > 0 {
+		if dir, err := cache.Dir(); err == nil {
+			c = &cache.Cache{Dir: dir, TTL: discoveryTTL}
+		}
+	}
+	if c != nil && !o.refresh {
+		if d, err := c.LoadDiscovery(o.cacheKey()); err == nil && d != nil {
+			o.openshift = &d.OpenShift
+			return d.OpenShift
+		}
+	}
+
+	err := o.withRetry(func() error {
+		_, err := clientset.Discovery().ServerResourcesForGroupVersion(projectResource.GroupVersion().String())
+		return err
+	})
+	found := err == nil
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err), apierrors.IsForbidden(err):
+	default:
+		fmt.Fprintf(o.ErrOut, "warning: failed to discover the project API, listing namespaces instead: %v\n", err)
+		return false
+	}
+
+	o.openshift = &found
+	if c != nil {
+		if err := c.SetDiscovery(o.cacheKey(), cache.Discovery{OpenShift: found}); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to update namespace cache: %v\n", err)
+		}
+	}
+	return found
+}
+
+// listProjects lists the OpenShift projects matching the selectors in
+// chunks and returns them as namespaces
+func (o *NsOptions) listProjects() (*v1.NamespaceList, error) {
+	client, err := o.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return o.listChunks(func(opts metav1.ListOptions) (*v1.NamespaceList, error) {
+		projects, err := client.Resource(projectResource).List(o.ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		// projects share the metadata, spec and status of namespaces
+		namespaces := &v1.NamespaceList{}
+		namespaces.ResourceVersion = projects.GetResourceVersion()
+		namespaces.Continue = projects.GetContinue()
+		for _, p := range projects.Items {
+			ns := v1.Namespace{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(p.Object, &ns); err != nil {
+				return nil, err
+			}
+			namespaces.Items = append(namespaces.Items, ns)
+		}
+		return namespaces, nil
+	})
+}
+
+// hasProjectDetails reports whether any of the namespaces has a display
+// name or a description
+func hasProjectDetails(namespaces []v1.Namespace) bool {
+	for _, ns := range namespaces {
+		annotations := ns.GetAnnotations()
+		if annotations[annotationDisplayName] != "" || annotations[annotationDescription] != "" {
+			return true
+		}
+	}
+	return false
+}
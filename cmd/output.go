@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputJSON = "json"
+	outputYAML = "yaml"
+	outputName = "name"
+	outputWide = "wide"
+
+	outputCustomColumns = "custom-columns="
+	outputGoTemplate    = "go-template="
+)
+
+var outputFormats = []string{outputJSON, outputYAML, outputName, outputWide, outputCustomColumns + "...", outputGoTemplate + "..."}
+
+// namespaceListing is the machine readable representation of the
+// namespace list
+type namespaceListing struct {
+	Context    string                 `json:"context"`
+	Current    string                 `json:"current"`
+	Namespaces []namespaceListingItem `json:"namespaces"`
+}
+
+type namespaceListingItem struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
+// currentNamespace is the machine readable representation of the current
+// namespace
+type currentNamespace struct {
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case "", outputJSON, outputYAML, outputName, outputWide:
+		return nil
+	}
+	for _, prefix := range []string{outputCustomColumns, outputGoTemplate} {
+		if strings.HasPrefix(output, prefix) {
+			if output == prefix {
+				return fmt.Errorf("output format \"%s\" requires a value", strings.TrimSuffix(prefix, "="))
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format \"%s\", allowed formats are: %s", output, strings.Join(outputFormats, "|"))
+}
+
+// printStructured prints the namespaces as json or yaml including the
+// current namespace marker
+func (o *NsOptions) printStructured(namespaces []v1.Namespace, currentNS string) error {
+	listing := namespaceListing{
+		Context:    o.contextName(),
+		Current:    currentNS,
+		Namespaces: make([]namespaceListingItem, 0, len(namespaces)),
+	}
+	for _, ns := range namespaces {
+		listing.Namespaces = append(listing.Namespaces, namespaceListingItem{
+			Name:    ns.GetName(),
+			Current: ns.GetName() == currentNS,
+		})
+	}
+
+	return o.printObject(listing)
+}
+
+// printCurrent prints the namespace of the current context, default is
+// used if the context does not define a namespace
+func (o *NsOptions) printCurrent() error {
+	if err := o.checkContext(); err != nil {
+		return err
+	}
+	current := currentNamespace{
+		Context:   o.contextName(),
+		Namespace: o.rawConfig.Contexts[o.contextName()].Namespace,
+	}
+	if current.Namespace == "" {
+		current.Namespace = "default"
+	}
+
+	if o.output == outputJSON || o.output == outputYAML {
+		return o.printObject(current)
+	}
+	_, err := fmt.Fprintln(o.Out, current.Namespace)
+	return err
+}
+
+// printObject prints obj as json or yaml depending on the output format
+func (o *NsOptions) printObject(obj interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+	if o.output == outputYAML {
+		data, err = yaml.Marshal(obj)
+	} else {
+		data, err = json.MarshalIndent(obj, "", "    ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = o.Out.Write(data)
+	return err
+}
+
+// printWide prints the namespaces as a table including status, age and
+// labels. The display names and descriptions of OpenShift projects and the
+// vclusters hosted by a namespace are shown if any namespace has them, the
+// Helm releases with --helm.
+func (o *NsOptions) printWide(namespaces []v1.Namespace, currentNS string) error {
+	details := o.namespaceDetails(namespaces)
+	projects := hasProjectDetails(namespaces)
+
+	o.markVClusters()
+	vclusters := o.hostedVClusters
+	// only --helm looks the releases up, listing the secrets of every
+	// namespace is expensive on large clusters
+	releases := o.namespaceReleases
+
+	var usage map[string]*resourceUsage
+	if o.metrics {
+		var found bool
+		var err error
+		usage, found, err = o.namespaceUsage(namespaces)
+		switch {
+		case err != nil:
+			fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+		case !found:
+			fmt.Fprintf(o.ErrOut, "warning: the metrics API %s is not available, install metrics-server to show the usage\n", metricsGroup)
+		}
+	}
+
+	header := []string{"CURRENT", "NAME"}
+	if projects {
+		header = append(header, "DISPLAY NAME", "DESCRIPTION")
+	}
+	header = append(header, "STATUS", "AGE", "PODS")
+	if o.counts {
+		header = append(header, "DEPLOYMENTS", "SERVICES")
+	}
+	if usage != nil {
+		header = append(header, "CPU", "MEMORY")
+	}
+	header = append(header, "QUOTA")
+	if len(vclusters) > 0 {
+		header = append(header, "VCLUSTERS")
+	}
+	if len(releases) > 0 {
+		header = append(header, "RELEASES")
+	}
+	for _, key := range o.showAnnotations {
+		header = append(header, annotationHeader(key))
+	}
+	header = append(header, "LABELS")
+
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for i, ns := range namespaces {
+		current := ""
+		if ns.GetName() == currentNS {
+			current = "*"
+		}
+		row := []string{current, ns.GetName()}
+		if projects {
+			annotations := ns.GetAnnotations()
+			row = append(row, annotations[annotationDisplayName], annotations[annotationDescription])
+		}
+		row = append(row, string(ns.Status.Phase), age(ns.GetCreationTimestamp().Time), details[i].pods)
+		if o.counts {
+			row = append(row, details[i].deployments, details[i].services)
+		}
+		if usage != nil {
+			if u, ok := usage[ns.GetName()]; ok {
+				row = append(row, formatCPU(u.cpu), formatMemory(u.memory))
+			} else {
+				row = append(row, "<unknown>", "<unknown>")
+			}
+		}
+		row = append(row, details[i].quota)
+		if len(vclusters) > 0 {
+			row = append(row, listOrNone(vclusters[ns.GetName()]))
+		}
+		if len(releases) > 0 {
+			row = append(row, listOrNone(releases[ns.GetName()]))
+		}
+		for _, key := range o.showAnnotations {
+			row = append(row, annotationValue(ns, key))
+		}
+		row = append(row, labels.FormatLabels(ns.GetLabels()))
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// listingColumns returns the header and the cells of the columns added to
+// the plain listing by --show-annotations and --show-labels
+func (o *NsOptions) listingColumns(namespaces []v1.Namespace) (header []string, rows [][]string) {
+	for _, key := range o.showAnnotations {
+		header = append(header, annotationHeader(key))
+	}
+	if o.showLabels {
+		header = append(header, "LABELS")
+	}
+
+	rows = make([][]string, len(namespaces))
+	for i, ns := range namespaces {
+		for _, key := range o.showAnnotations {
+			rows[i] = append(rows[i], annotationValue(ns, key))
+		}
+		if o.showLabels {
+			rows[i] = append(rows[i], labels.FormatLabels(ns.GetLabels()))
+		}
+	}
+	return header, rows
+}
+
+// annotationHeader returns the column header of an annotation, the prefix
+// of the key is omitted like kubectl does for label columns
+func annotationHeader(key string) string {
+	return strings.ToUpper(key[strings.LastIndex(key, "/")+1:])
+}
+
+// annotationValue returns the value of the annotation of a namespace or
+// <none> if it is not set
+func annotationValue(ns v1.Namespace, key string) string {
+	if value, ok := ns.GetAnnotations()[key]; ok {
+		return value
+	}
+	return "<none>"
+}
+
+// columnWidths returns the visible width of the widest cell of every
+// column
+func columnWidths(header []string, rows [][]string) []int {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if w := visibleWidth(cell); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+// padColumns joins the cells separated by two spaces, colors don't take
+// space so the padding is based on the visible width
+func padColumns(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, cell := range cells {
+		if i == len(cells)-1 {
+			b.WriteString(cell)
+			break
+		}
+		b.WriteString(cell)
+		b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+2))
+	}
+	return b.String()
+}
+
+// listOrNone joins the items with commas, <none> is returned if there are
+// no items
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "<none>"
+	}
+	return strings.Join(items, ",")
+}
+
+// age returns the human readable time since t
+func age(t time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t))
+}
+
+// printGoTemplate renders the namespaces as v1.NamespaceList with the given
+// go template
+func (o *NsOptions) printGoTemplate(namespaces []v1.Namespace, tmpl string) error {
+	p, err := printers.NewGoTemplatePrinter([]byte(tmpl))
+	if err != nil {
+		return err
+	}
+
+	list := &v1.NamespaceList{Items: namespaces}
+	list.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("NamespaceList"))
+
+	return p.PrintObj(list, o.Out)
+}
+
+// printCustomColumns prints a table with columns defined by spec, a comma
+// separated list of HEADER:JSONPATH pairs like kubectl does
+func (o *NsOptions) printCustomColumns(namespaces []v1.Namespace, spec string) error {
+	headers := []string{}
+	paths := []*jsonpath.JSONPath{}
+	for _, column := range strings.Split(spec, ",") {
+		parts := strings.SplitN(column, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", column)
+		}
+		p := jsonpath.New(parts[0]).AllowMissingKeys(true)
+		if err := p.Parse(relaxedJSONPath(parts[1])); err != nil {
+			return fmt.Errorf("invalid json path \"%s\": %w", parts[1], err)
+		}
+		headers = append(headers, parts[0])
+		paths = append(paths, p)
+	}
+
+	w := tabwriter.NewWriter(o.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	for i := range namespaces {
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&namespaces[i])
+		if err != nil {
+			return err
+		}
+
+		values := make([]string, 0, len(paths))
+		for _, p := range paths {
+			results, err := p.FindResults(obj)
+			if err != nil {
+				return err
+			}
+			value := []string{}
+			for _, r := range results {
+				for _, v := range r {
+					value = append(value, fmt.Sprintf("%v", v.Interface()))
+				}
+			}
+			if len(value) == 0 {
+				value = append(value, "<none>")
+			}
+			values = append(values, strings.Join(value, ","))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+
+	return w.Flush()
+}
+
+// relaxedJSONPath turns expressions like .metadata.name or metadata.name
+// into the template syntax {.metadata.name} expected by jsonpath
+func relaxedJSONPath(path string) string {
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		return path
+	}
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+func namespaceNames(namespaces []v1.Namespace) []string {
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.GetName())
+	}
+	return names
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const pickerHeight = 10
+
+// errPickerAborted is returned when the user leaves the picker without
+// selecting an entry
+var errPickerAborted = fmt.Errorf("selection aborted")
+
+// isTerminal reports whether the given stream is attached to a terminal
+func isTerminal(stream interface{}) bool {
+	f, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+	return terminal.IsTerminal(int(f.Fd()))
+}
+
+// picker is a minimal interactive fuzzy finder rendered on a terminal
+type picker struct {
+	in  *os.File
+	out io.Writer
+
+	items   []string
+	initial string
+	// alternate is the second order of the items which tab toggles to,
+	// the labels describe the orders in the status line
+	alternate      []string
+	label          string
+	alternateLabel string
+	query          []rune
+	matches        []string
+	cursor         int
+	offset         int
+}
+
+// pick lets the user interactively select one of items, the cursor
+// initially points to the initial item if present
+func pick(in *os.File, out io.Writer, items []string, initial string) (string, error) {
+	p := &picker{
+		in:      in,
+		out:     out,
+		items:   items,
+		initial: initial,
+	}
+	return p.run()
+}
+
+// pickRecent is like pick, the items are offered in the most recently used
+// order first and tab toggles to the sorted order
+func pickRecent(in *os.File, out io.Writer, sorted, recent []string, initial string) (string, error) {
+	p := &picker{
+		in:             in,
+		out:            out,
+		items:          recent,
+		initial:        initial,
+		alternate:      sorted,
+		label:          "recently used",
+		alternateLabel: "sorted",
+	}
+	return p.run()
+}
+
+func (p *picker) run() (string, error) {
+	fd := int(p.in.Fd())
+	state, err := terminal.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer terminal.Restore(fd, state)
+
+	p.filter()
+	for i, item := range p.matches {
+		if item == p.initial {
+			p.moveTo(i)
+		}
+	}
+
+	r := bufio.NewReader(p.in)
+	for {
+		p.render()
+
+		c, _, err := r.ReadRune()
+		if err != nil {
+			p.clear()
+			return "", err
+		}
+
+		switch c {
+		case '\r', '\n':
+			p.clear()
+			if len(p.matches) == 0 {
+				return "", errPickerAborted
+			}
+			return p.matches[p.cursor], nil
+		case 3, 4: // ctrl-c, ctrl-d
+			p.clear()
+			return "", errPickerAborted
+		case 27: // escape or an escape sequence
+			if r.Buffered() == 0 {
+				p.clear()
+				return "", errPickerAborted
+			}
+			seq := make([]byte, 2)
+			if _, err := io.ReadFull(r, seq); err != nil {
+				p.clear()
+				return "", err
+			}
+			switch {
+			case seq[0] == '[' && seq[1] == 'A', seq[0] == 'O' && seq[1] == 'A':
+				p.moveTo(p.cursor - 1)
+			case seq[0] == '[' && seq[1] == 'B', seq[0] == 'O' && seq[1] == 'B':
+				p.moveTo(p.cursor + 1)
+			}
+		case '\t':
+			p.toggleOrder()
+		case 16: // ctrl-p
+			p.moveTo(p.cursor - 1)
+		case 14: // ctrl-n
+			p.moveTo(p.cursor + 1)
+		case 127, 8: // backspace
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case 21: // ctrl-u
+			p.query = p.query[:0]
+			p.filter()
+		default:
+			if unicode.IsPrint(c) {
+				p.query = append(p.query, c)
+				p.filter()
+			}
+		}
+	}
+}
+
+// filter updates the matching items based on the current query
+func (p *picker) filter() {
+	p.matches = fuzzyFilter(string(p.query), p.items)
+	p.cursor, p.offset = 0, 0
+}
+
+// toggleOrder switches to the alternate order of the items, the cursor
+// stays on the selected item
+func (p *picker) toggleOrder() {
+	if p.alternate == nil {
+		return
+	}
+	selected := ""
+	if len(p.matches) > 0 {
+		selected = p.matches[p.cursor]
+	}
+	p.items, p.alternate = p.alternate, p.items
+	p.label, p.alternateLabel = p.alternateLabel, p.label
+	p.filter()
+	for i, item := range p.matches {
+		if item == selected {
+			p.moveTo(i)
+		}
+	}
+}
+
+func (p *picker) moveTo(i int) {
+	if i < 0 || i >= len(p.matches) {
+		return
+	}
+	p.cursor = i
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+pickerHeight {
+		p.offset = p.cursor - pickerHeight + 1
+	}
+}
+
+// render draws the prompt and the visible part of the matches, the
+// terminal is in raw mode so every line has to be terminated by \r\n
+func (p *picker) render() {
+	var b strings.Builder
+
+	b.WriteString("\r\x1b[J")
+	fmt.Fprintf(&b, "> %s", string(p.query))
+
+	lines := 0
+	for i := p.offset; i < len(p.matches) && i < p.offset+pickerHeight; i++ {
+		b.WriteString("\r\n")
+		if i == p.cursor {
+			fmt.Fprintf(&b, "\x1b[7m> %s\x1b[0m", p.matches[i])
+		} else {
+			fmt.Fprintf(&b, "  %s", p.matches[i])
+		}
+		lines++
+	}
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "  %d/%d", len(p.matches), len(p.items))
+	if p.alternate != nil {
+		fmt.Fprintf(&b, "  %s (tab: %s)", p.label, p.alternateLabel)
+	}
+	lines++
+
+	// move back to the prompt line behind the query
+	fmt.Fprintf(&b, "\x1b[%dA\r\x1b[%dC", lines, len(p.query)+2)
+
+	fmt.Fprint(p.out, b.String())
+}
+
+func (p *picker) clear() {
+	fmt.Fprint(p.out, "\r\x1b[J")
+}
+
+// fuzzyFilter returns all items matching the query as a subsequence, best
+// matches first. Items with an equal score keep their original order.
+func fuzzyFilter(query string, items []string) []string {
+	if query == "" {
+		return append([]string{}, items...)
+	}
+
+	type match struct {
+		item  string
+		score int
+	}
+	matches := []match{}
+	for _, item := range items {
+		if score, ok := fuzzyScore(query, item); ok {
+			matches = append(matches, match{item: item, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]string, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.item)
+	}
+	return result
+}
+
+// fuzzyScore reports whether all characters of query appear in item in the
+// same order. Consecutive characters and matches at the start of a word
+// are rewarded, gaps are penalized.
+func fuzzyScore(query, item string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	s := []rune(strings.ToLower(item))
+
+	score, qi, last := 0, 0, -1
+	for si := 0; si < len(s) && qi < len(q); si++ {
+		if s[si] != q[qi] {
+			continue
+		}
+		switch {
+		case last >= 0 && si == last+1:
+			score += 5
+		case si == 0 || s[si-1] == '-' || s[si-1] == '.':
+			score += 3
+		default:
+			score++
+		}
+		if last >= 0 {
+			score -= si - last - 1
+		}
+		last = si
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
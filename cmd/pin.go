@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/postfinance/kubectl-ns/pkg/project"
+	"github.com/postfinance/kubectl-ns/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pinExample = `
+	# pin the current namespace to the current directory
+	kubectl ns pin
+
+	# pin the namespace foo of the context prod to the current directory
+	kubectl ns pin foo --with-context --context prod
+
+	# trust the project file of a cloned repository after checking its content
+	kubectl ns pin --allow`
+)
+
+// PinOptions provides information required to write a project file
+type PinOptions struct {
+	ns *NsOptions
+
+	namespace   string
+	withContext bool
+	allow       bool
+}
+
+// NewPinCmd provides a cobra command writing a .kubens file pinning a
+// namespace to the current directory
+func NewPinCmd(ns *NsOptions) *cobra.Command {
+	opt := &PinOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "pin [namespace]",
+		Short:        "Pin a namespace to the current directory",
+		Example:      pinExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.withContext, "with-context", false, "pin the context as well")
+	cmd.Flags().BoolVar(&opt.allow, "allow", false, "trust the existing project file of the current directory or its parents for --auto")
+
+	return cmd
+}
+
+// Complete sets all information required for writing the project file
+func (o *PinOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		o.namespace = args[0]
+	}
+
+	return o.ns.loadConfig()
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *PinOptions) Validate() error {
+	if o.allow {
+		if o.namespace != "" || o.withContext {
+			return fmt.Errorf("--allow accepts no namespace argument and can't be combined with --with-context")
+		}
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+
+	if o.namespace == "" {
+		o.namespace = o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace
+	}
+	if o.namespace == "" {
+		o.namespace = "default"
+	}
+
+	return nil
+}
+
+// Run writes the project file to the current directory and trusts it,
+// --dry-run prints its content instead
+func (o *PinOptions) Run() error {
+	if o.allow {
+		return o.allowProject()
+	}
+
+	f := &project.File{Namespace: o.namespace}
+	if o.withContext {
+		f.Context = o.ns.contextName()
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if o.ns.dryRun {
+		fmt.Fprintf(o.ns.Out, "\"%s\" would be pinned in %s\n", f, filepath.Join(dir, project.FileName))
+		return nil
+	}
+	if err := f.Write(dir); err != nil {
+		return fmt.Errorf("failed to write project file: %w", err)
+	}
+	if err := trustProject(f); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.ns.Out, "pinned \"%s\" in %s\n", f, f.Path)
+
+	return nil
+}
+
+// allowProject trusts the project file found in the current directory or
+// its parents
+func (o *PinOptions) allowProject() error {
+	f, err := project.Find(".")
+	if err != nil {
+		return err
+	}
+	if f == nil {
+		return fmt.Errorf("no project file found in the current directory or its parents")
+	}
+	if o.ns.dryRun {
+		fmt.Fprintf(o.ns.Out, "\"%s\" pinned in %s would be allowed\n", f, f.Path)
+		return nil
+	}
+	if err := trustProject(f); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.ns.Out, "allowed \"%s\" pinned in %s\n", f, f.Path)
+	return nil
+}
+
+// trustProject remembers the content of f as trusted
+func trustProject(f *project.File) error {
+	err := state.UpdateDefault(func(s *state.State) (bool, error) {
+		return s.AllowProject(f.Path, f.Sum), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to allow project file: %w", err)
+	}
+	return nil
+}
+
+// projectNamespace sets the user specified namespace to the one pinned by a
+// project file in the current directory or one of its parents, found is
+// false if there is no project file. A cloned repository could switch the
+// context to production, so only files allowed by the user are used.
+func (o *NsOptions) projectNamespace() (found bool, err error) {
+	f, err := project.Find(".")
+	if err != nil || f == nil {
+		return false, err
+	}
+
+	s, err := state.LoadDefault()
+	if err != nil {
+		return false, fmt.Errorf("failed to load state: %w", err)
+	}
+	if !s.IsAllowedProject(f.Path, f.Sum) {
+		fmt.Fprintf(o.ErrOut, "warning: %s is not allowed, check its content and run \"kubectl ns pin --allow\" to use it\n", f.Path)
+		return false, nil
+	}
+	o.userSpecifiedNamespace = f.String()
+	return true, nil
+}
+
+// pinnedIsCurrent reports whether the pinned namespace and context are
+// already active, so the shell hook doesn't contact the API server
+func (o *NsOptions) pinnedIsCurrent() bool {
+	current := o.rawConfig.Contexts[o.contextName()].Namespace
+	if current == "" {
+		current = "default"
+	}
+	return o.switchContext == "" && current == o.userSpecifiedNamespace
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+const (
+	promptContextColor   = "36" // cyan
+	promptNamespaceColor = "33" // yellow
+)
+
+var (
+	promptExample = `
+	# show context and namespace in the bash prompt
+	PS1='[$(kubectl-ns prompt --color --shell bash)] \$ '
+
+	# show context and namespace in the zsh prompt
+	setopt PROMPT_SUBST
+	PROMPT='[$(kubectl-ns prompt --color --shell zsh)] %# '`
+)
+
+// PromptOptions provides information required to print the prompt
+type PromptOptions struct {
+	ns        *NsOptions
+	color     bool
+	shell     string
+	separator string
+}
+
+// NewPromptCmd provides a cobra command printing the current context and
+// namespace for shell prompts
+func NewPromptCmd(ns *NsOptions) *cobra.Command {
+	opt := &PromptOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "prompt",
+		Short:        "Print context and namespace for shell prompts without accessing the API server",
+		Example:      promptExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+			if err := opt.Run(); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.color, "color", false, "colorize context and namespace")
+	cmd.Flags().StringVar(&opt.shell, "shell", "", "mark color sequences as non-printing for the prompt of the shell, one of: bash|zsh")
+	cmd.Flags().StringVar(&opt.separator, "separator", "/", "separator between context and namespace")
+
+	return cmd
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *PromptOptions) Validate() error {
+	switch o.shell {
+	case "", "bash", "zsh":
+		return nil
+	}
+	return fmt.Errorf("unsupported shell \"%s\", use one of: bash|zsh", o.shell)
+}
+
+// Run prints the prompt, nothing is printed if no context is active
+func (o *PromptOptions) Run() error {
+	p, err := o.load()
+	if err != nil {
+		return err
+	}
+	if p.Context == "" {
+		return nil
+	}
+
+	namespace := p.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	_, err = fmt.Fprintf(o.ns.Out, "%s%s%s\n", o.colorize(promptContextColor, p.Context), o.separator, o.colorize(promptNamespaceColor, namespace))
+	return err
+}
+
+// load returns context and namespace from the prompt cache and parses the
+// kubeconfig files only if one of them changed since the last call. The
+// cache only holds the current context, a context selected by --context is
+// read from the kubeconfig unless it is the cached one.
+func (o *PromptOptions) load() (*cache.Prompt, error) {
+	files := cache.Stat(o.ns.kubeconfigPaths())
+	context := *o.ns.configFlags.Context
+
+	c, err := cache.New(0)
+	if err != nil {
+		return nil, err
+	}
+	if p, err := c.LoadPrompt(); err == nil && p != nil && p.Valid(files) && (context == "" || p.Context == context) {
+		return p, nil
+	}
+
+	rawConfig, err := o.ns.configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return nil, err
+	}
+	if context == "" {
+		context = rawConfig.CurrentContext
+	}
+	p := &cache.Prompt{
+		Files:   files,
+		Context: context,
+	}
+	if ctx, ok := rawConfig.Contexts[context]; ok {
+		p.Namespace = ctx.Namespace
+	}
+
+	if context != rawConfig.CurrentContext {
+		return p, nil
+	}
+	if err := c.SetPrompt(p); err != nil {
+		fmt.Fprintf(o.ns.ErrOut, "warning: failed to cache the prompt: %v\n", err)
+	}
+	return p, nil
+}
+
+// colorize wraps s into the color escape sequence, the sequences are marked
+// as non-printing for the configured shell to keep line editing intact
+func (o *PromptOptions) colorize(code, s string) string {
+	if !o.color {
+		return s
+	}
+
+	start, end := "", ""
+	switch o.shell {
+	case "bash":
+		start, end = "\001", "\002"
+	case "zsh":
+		start, end = "%{", "%}"
+	}
+	return fmt.Sprintf("%s\x1b[%sm%s%s%s\x1b[0m%s", start, code, end, s, start, end)
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// quotaBarWidth is the number of characters of a usage bar
+	quotaBarWidth = 20
+	// quotaWarning and quotaCritical are the usages in percent from which
+	// bars are shown in yellow and red
+	quotaWarning  = 75
+	quotaCritical = 90
+)
+
+var (
+	quotaExample = `
+	# show the quota usage of the current namespace
+	kubectl ns quota
+
+	# show the quota usage of the namespace foo
+	kubectl ns quota foo`
+)
+
+// QuotaOptions provides information required to show the quota usage of a
+// namespace
+type QuotaOptions struct {
+	ns   *NsOptions
+	name string
+}
+
+// NewQuotaCmd provides a cobra command showing the hard limits and the
+// usage of the resource quotas of a namespace
+func NewQuotaCmd(ns *NsOptions) *cobra.Command {
+	opt := &QuotaOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "quota [namespace]",
+		Short:        "Show the resource quota usage of the current or a given namespace",
+		Example:      quotaExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	return cmd
+}
+
+// Complete sets all information required for showing the quota usage, the
+// current namespace is used if none is given
+func (o *QuotaOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+	o.name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *QuotaOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+
+	return nil
+}
+
+// Run prints every hard limit of the resource quotas with its usage,
+// followed by the defaults and bounds of the limit ranges which apply to
+// the containers of new pods
+func (o *QuotaOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+	var quotas *v1.ResourceQuotaList
+	err = o.ns.withRetry(func() (err error) {
+		quotas, err = clientset.CoreV1().ResourceQuotas(o.name).List(o.ns.ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get resource quotas: %w", err)
+	}
+	var limits *v1.LimitRangeList
+	err = o.ns.withRetry(func() (err error) {
+		limits, err = clientset.CoreV1().LimitRanges(o.name).List(o.ns.ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get limit ranges: %w", err)
+	}
+	if len(quotas.Items) == 0 && len(limits.Items) == 0 {
+		fmt.Fprintf(o.ns.ErrOut, "no resource quotas or limit ranges found in namespace \"%s\"\n", o.name)
+		return nil
+	}
+
+	// the bar is the last column, colors don't disturb the alignment
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	if len(quotas.Items) > 0 {
+		fmt.Fprintln(w, "QUOTA\tRESOURCE\tUSED\tHARD\tUSAGE")
+		for _, q := range quotas.Items {
+			for _, name := range sortedResourceNames(q.Status.Hard) {
+				used := q.Status.Used[name]
+				hard := q.Status.Hard[name]
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", q.GetName(), name, used.String(), hard.String(), usageBar(used.MilliValue(), hard.MilliValue()))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(limits.Items) > 0 {
+		if len(quotas.Items) > 0 {
+			fmt.Fprintln(o.ns.Out)
+		}
+		fmt.Fprintln(w, "LIMIT RANGE\tTYPE\tRESOURCE\tMIN\tMAX\tDEFAULT REQUEST\tDEFAULT LIMIT")
+		for _, l := range limits.Items {
+			for _, item := range l.Spec.Limits {
+				for _, r := range limitResourceNames(item) {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", l.GetName(), item.Type, r,
+						quantity(item.Min, r), quantity(item.Max, r), quantity(item.DefaultRequest, r), quantity(item.Default, r))
+				}
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// usageBar renders the usage of a hard limit as bar followed by the usage
+// in percent, high usages are colored
+func usageBar(used, hard int64) string {
+	if hard <= 0 {
+		return strings.Repeat(" ", quotaBarWidth+2) + "   -"
+	}
+	percent := float64(used) * 100 / float64(hard)
+	filled := int(percent * quotaBarWidth / 100)
+	if filled > quotaBarWidth {
+		filled = quotaBarWidth
+	}
+	bar := "[" + strings.Repeat("#", filled) + strings.Repeat(" ", quotaBarWidth-filled) + "]"
+	switch {
+	case percent >= quotaCritical:
+		bar = color.RedString(bar)
+	case percent >= quotaWarning:
+		bar = color.YellowString(bar)
+	}
+	return fmt.Sprintf("%s %3.0f%%", bar, percent)
+}
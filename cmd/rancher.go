@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// annotationRancherProject is set by Rancher on the namespaces of a project,
+// its value is the cluster and project ID, e.g. c-m8k2p:p-5xqzv
+const annotationRancherProject = "field.cattle.io/projectId"
+
+// namespaceProject returns the ID of the Rancher project of the namespace
+// without the cluster ID
+func namespaceProject(ns v1.Namespace) string {
+	id := ns.GetAnnotations()[annotationRancherProject]
+	if i := strings.LastIndex(id, ":"); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
+
+// filterProject removes all namespaces which don't belong to the project,
+// the project is given either by its ID or including the cluster ID
+func (o *NsOptions) filterProject(namespaces []v1.Namespace) []v1.Namespace {
+	result := make([]v1.Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if namespaceProject(ns) == o.project || ns.GetAnnotations()[annotationRancherProject] == o.project {
+			result = append(result, ns)
+		}
+	}
+	return result
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// groupAuthenticated is the group of every authenticated user
+const groupAuthenticated = "system:authenticated"
+
+// selfSubjectReviewVersions are the versions of the SelfSubjectReview API
+// tried in order, it is available since Kubernetes 1.26
+var selfSubjectReviewVersions = []string{"v1", "v1beta1", "v1alpha1"}
+
+var (
+	rbacExample = `
+	# list the role bindings of the current namespace which apply to you
+	kubectl ns rbac
+
+	# list all role bindings of the namespace foo
+	kubectl ns rbac foo --all`
+)
+
+// userInfo identifies the user of the current context
+type userInfo struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups"`
+}
+
+// RBACOptions provides information required to summarize the role bindings
+// of a namespace
+type RBACOptions struct {
+	ns   *NsOptions
+	name string
+
+	all bool
+}
+
+// NewRBACCmd provides a cobra command listing the role bindings of a
+// namespace together with the rules they grant
+func NewRBACCmd(ns *NsOptions) *cobra.Command {
+	opt := &RBACOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "rbac [namespace]",
+		Short:        "List the role bindings of a namespace which apply to you",
+		Example:      rbacExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&opt.all, "all", "A", false, "list the role bindings of all subjects")
+
+	return cmd
+}
+
+// Complete sets all information required for listing the role bindings,
+// the current namespace is used if none is given
+func (o *RBACOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+		return nil
+	}
+
+	if err := o.ns.checkContext(); err != nil {
+		return err
+	}
+	o.name = namespaceOrDefault(o.ns.rawConfig.Contexts[o.ns.contextName()].Namespace)
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *RBACOptions) Validate() error {
+	if o.name == "" {
+		return fmt.Errorf("namespace must not be empty")
+	}
+
+	return nil
+}
+
+// Run prints the matching role bindings sorted by name, each followed by
+// the rules of its role
+func (o *RBACOptions) Run() error {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return err
+	}
+
+	var user *userInfo
+	if !o.all {
+		if user, err = o.ns.whoami(); err != nil {
+			fmt.Fprintf(o.ns.ErrOut, "warning: can't determine your user, the role bindings of all subjects are listed: %v\n", err)
+		}
+	}
+
+	var bindings *rbacv1.RoleBindingList
+	err = o.ns.withRetry(func() (err error) {
+		bindings, err = clientset.RbacV1().RoleBindings(o.name).List(o.ns.ctx, metav1.ListOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	sort.Slice(bindings.Items, func(i, j int) bool {
+		return bindings.Items[i].Name < bindings.Items[j].Name
+	})
+
+	printed := 0
+	for _, b := range bindings.Items {
+		if user != nil && !user.boundBy(b) {
+			continue
+		}
+		if printed > 0 {
+			fmt.Fprintln(o.ns.Out)
+		}
+		printed++
+
+		fmt.Fprintf(o.ns.Out, "%s: %s/%s\n", b.Name, b.RoleRef.Kind, b.RoleRef.Name)
+		fmt.Fprintf(o.ns.Out, "  subjects: %s\n", subjectsSummary(b.Subjects))
+		rules, err := o.roleRules(b)
+		if err != nil {
+			fmt.Fprintf(o.ns.Out, "  rules: <unknown> (%v)\n", err)
+			continue
+		}
+		for _, rule := range rules {
+			fmt.Fprintf(o.ns.Out, "  %s\n", ruleSummary(rule))
+		}
+	}
+
+	if printed == 0 {
+		if user != nil {
+			fmt.Fprintf(o.ns.ErrOut, "no role bindings for user \"%s\" found in namespace \"%s\"\n", user.Username, o.name)
+		} else {
+			fmt.Fprintf(o.ns.ErrOut, "no role bindings found in namespace \"%s\"\n", o.name)
+		}
+	}
+	return nil
+}
+
+// roleRules returns the rules of the Role or ClusterRole referenced by the
+// binding
+func (o *RBACOptions) roleRules(b rbacv1.RoleBinding) ([]rbacv1.PolicyRule, error) {
+	clientset, err := o.ns.client()
+	if err != nil {
+		return nil, err
+	}
+	var rules []rbacv1.PolicyRule
+	err = o.ns.withRetry(func() error {
+		switch b.RoleRef.Kind {
+		case "Role":
+			role, err := clientset.RbacV1().Roles(b.Namespace).Get(o.ns.ctx, b.RoleRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			rules = role.Rules
+		case "ClusterRole":
+			role, err := clientset.RbacV1().ClusterRoles().Get(o.ns.ctx, b.RoleRef.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			rules = role.Rules
+		default:
+			return fmt.Errorf("unsupported role kind %s", b.RoleRef.Kind)
+		}
+		return nil
+	})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, fmt.Errorf("the role does not exist")
+	case apierrors.IsForbidden(err):
+		return nil, fmt.Errorf("not allowed to get the role")
+	}
+	return rules, err
+}
+
+// whoami returns the user of the current context as seen by the API server
+func (o *NsOptions) whoami() (*userInfo, error) {
+	clientset, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, version := range selfSubjectReviewVersions {
+		body := fmt.Sprintf(`{"apiVersion":"authentication.k8s.io/%s","kind":"SelfSubjectReview"}`, version)
+		data, err := clientset.AuthenticationV1().RESTClient().Post().
+			AbsPath("/apis/authentication.k8s.io", version, "selfsubjectreviews").
+			Body([]byte(body)).
+			DoRaw(o.ctx)
+		if apierrors.IsNotFound(err) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		review := struct {
+			Status struct {
+				UserInfo userInfo `json:"userInfo"`
+			} `json:"status"`
+		}{}
+		if err := json.Unmarshal(data, &review); err != nil {
+			return nil, err
+		}
+		return &review.Status.UserInfo, nil
+	}
+
+	// impersonation identifies the user without asking the API server
+	if as, groups := o.impersonation(); as != "" {
+		return &userInfo{Username: as, Groups: append(groups, groupAuthenticated)}, nil
+	}
+	return nil, fmt.Errorf("SelfSubjectReview is not supported by the API server: %w", lastErr)
+}
+
+// boundBy reports whether the binding has the user or one of its groups as
+// subject
+func (u *userInfo) boundBy(b rbacv1.RoleBinding) bool {
+	for _, s := range b.Subjects {
+		switch s.Kind {
+		case rbacv1.UserKind:
+			if s.Name == u.Username {
+				return true
+			}
+		case rbacv1.GroupKind:
+			if s.Name == groupAuthenticated || containsString(u.Groups, s.Name) {
+				return true
+			}
+		case rbacv1.ServiceAccountKind:
+			namespace := s.Namespace
+			if namespace == "" {
+				namespace = b.Namespace
+			}
+			if "system:serviceaccount:"+namespace+":"+s.Name == u.Username {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// subjectsSummary lists the subjects of a binding like Group/team-a
+func subjectsSummary(subjects []rbacv1.Subject) string {
+	if len(subjects) == 0 {
+		return "<none>"
+	}
+	names := make([]string, 0, len(subjects))
+	for _, s := range subjects {
+		name := s.Name
+		if s.Kind == rbacv1.ServiceAccountKind && s.Namespace != "" {
+			name = s.Namespace + "/" + s.Name
+		}
+		names = append(names, s.Kind+"/"+name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ruleSummary describes a rule by its verbs and the resources qualified
+// with their API groups, like get,list deployments.apps
+func ruleSummary(rule rbacv1.PolicyRule) string {
+	verbs := strings.Join(rule.Verbs, ",")
+	if len(rule.NonResourceURLs) > 0 {
+		return verbs + " " + strings.Join(rule.NonResourceURLs, ",")
+	}
+
+	resources := []string{}
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			if group != "" {
+				resource += "." + group
+			}
+			resources = append(resources, resource)
+		}
+	}
+	summary := verbs + " " + strings.Join(resources, ",")
+	if len(rule.ResourceNames) > 0 {
+		summary += " (" + strings.Join(rule.ResourceNames, ",") + ")"
+	}
+	return summary
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/postfinance/kubectl-ns/pkg/backup"
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreExample = `
+	# list the kubeconfig backups
+	kubectl ns restore --list
+
+	# restore the most recent backup
+	kubectl ns restore
+
+	# restore backup 3 of the list without confirmation
+	kubectl ns restore 3 --yes`
+)
+
+// RestoreOptions provides information required to restore a kubeconfig
+// backup
+type RestoreOptions struct {
+	ns    *NsOptions
+	store *backup.Store
+
+	index int
+	list  bool
+	yes   bool
+}
+
+// NewRestoreCmd provides a cobra command restoring the kubeconfig from a
+// backup
+func NewRestoreCmd(ns *NsOptions) *cobra.Command {
+	opt := &RestoreOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "restore [backup]",
+		Short:        "Restore the kubeconfig from a backup",
+		Example:      restoreExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.list, "list", false, "list the backups instead of restoring one")
+	cmd.Flags().BoolVarP(&opt.yes, "yes", "y", false, "restore without confirmation")
+
+	return cmd
+}
+
+// Complete sets all information required for accessing the backups. The
+// kubeconfig itself is not loaded, it may be broken.
+func (o *RestoreOptions) Complete(cmd *cobra.Command, args []string) error {
+	if len(args) > 0 {
+		index, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid backup \"%s\", use the number shown by --list", args[0])
+		}
+		o.index = index
+	}
+
+	var err error
+	if o.ns.config, err = config.LoadDefault(); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	o.store, err = backup.NewDefaultStore(o.ns.backupsKept())
+
+	return err
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *RestoreOptions) Validate() error {
+	if o.index < 0 {
+		return fmt.Errorf("invalid backup %d", o.index)
+	}
+	if o.list && o.index > 0 {
+		return fmt.Errorf("--list can't be combined with a backup")
+	}
+
+	return nil
+}
+
+// Run lists the backups or restores one of them, by default the most
+// recent. The current kubeconfig is backed up first, so a restore can be
+// rolled back as well.
+func (o *RestoreOptions) Run() error {
+	backups, err := o.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read backups: %w", err)
+	}
+
+	if o.list {
+		return o.printBackups(backups)
+	}
+
+	if len(backups) == 0 {
+		return fmt.Errorf("no backups found")
+	}
+	index := o.index
+	if index == 0 {
+		index = len(backups)
+	}
+	if index > len(backups) {
+		return fmt.Errorf("backup %d does not exist", index)
+	}
+	b := backups[index-1]
+
+	if o.ns.dryRun {
+		for _, path := range backupPaths(b) {
+			fmt.Fprintf(o.ns.Out, "%s would be restored from the backup of %s\n", path, b.Time.Local().Format("2006-01-02 15:04:05"))
+		}
+		return nil
+	}
+
+	if !o.yes {
+		ok, err := o.ns.confirm(fmt.Sprintf("restore %s from the backup of %s?", strings.Join(backupPaths(b), ", "), b.Time.Local().Format("2006-01-02 15:04:05")))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	// the new backup may prune the one which is restored
+	contents, err := b.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if err := o.ns.backupKubeconfig(backupPaths(b)); err != nil {
+		return fmt.Errorf("failed to back up the current kubeconfig: %w", err)
+	}
+	if err := backup.Restore(contents); err != nil {
+		return err
+	}
+	for _, path := range backupPaths(b) {
+		fmt.Fprintf(o.ns.Out, "%s restored\n", path)
+	}
+	return nil
+}
+
+func (o *RestoreOptions) printBackups(backups []backup.Backup) error {
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tTIME\tFILES")
+	for i, b := range backups {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", i+1, b.Time.Local().Format("2006-01-02 15:04:05"), strings.Join(backupPaths(b), ","))
+	}
+
+	return w.Flush()
+}
+
+// backupPaths returns the original locations of the files of a backup
+func backupPaths(b backup.Backup) []string {
+	paths := make([]string, 0, len(b.Files))
+	for _, f := range b.Files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// defaultRetries is the number of retries of a failed request
+	defaultRetries = 3
+	// defaultRetryBackoff is the delay before the first retry, it doubles
+	// with every further retry
+	defaultRetryBackoff = 200 * time.Millisecond
+)
+
+// withRetry calls fn until it succeeds, fails with an error which is not
+// transient or the retries are exhausted
+func (o *NsOptions) withRetry(fn func() error) error {
+	backoff := wait.Backoff{
+		Duration: o.retryBackoff,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    o.retries + 1,
+	}
+	return retry.OnError(backoff, func(err error) bool {
+		return o.ctx.Err() == nil && isTransient(err)
+	}, fn)
+}
+
+// isTransient reports whether err is caused by a temporary problem of the
+// control plane, timeouts are not retried to fail fast
+func isTransient(err error) bool {
+	if status, ok := err.(apierrors.APIStatus); ok {
+		code := status.Status().Code
+		return code == 429 || (code >= 500 && code != 504)
+	}
+	return utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err)
+}
@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	"github.com/postfinance/kubectl-ns/pkg/state"
+	"github.com/spf13/cobra"
+)
+
+// NewRevertCmd provides a hidden cobra command applying the pending reverts
+// of temporary namespace switches as soon as they are due. It is started
+// in the background by --for.
+func NewRevertCmd(ns *NsOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:          "revert-pending",
+		Short:        "Revert temporary namespace switches when they expire",
+		Hidden:       true,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			for {
+				s, err := state.LoadDefault()
+				if err != nil {
+					return err
+				}
+				next, ok := s.NextRevert()
+				if !ok {
+					return nil
+				}
+
+				select {
+				case <-time.After(time.Until(next)):
+				case <-ns.ctx.Done():
+					return ns.ctx.Err()
+				}
+				if err := ns.applyDueReverts(ioutil.Discard); err != nil {
+					return err
+				}
+			}
+		},
+	}
+}
+
+// temporarySwitch takes care of reverting a switch from previous to newNS,
+// either in the background after the duration of --for or after the shell
+// started by --until-exit exited
+func (o *NsOptions) temporarySwitch(previous, newNS string) error {
+	name := o.contextName()
+	if o.untilExit {
+		fmt.Fprintf(o.ErrOut, "starting a shell, the namespace is reverted to \"%s\" when it exits\n", previous)
+		err := o.runChild(shellCommand(), "")
+		if revertErr := o.revertNamespace(state.Revert{Context: name, Namespace: newNS, Previous: previous}, o.ErrOut); revertErr != nil {
+			return revertErr
+		}
+		return err
+	}
+
+	paths, err := o.absoluteKubeconfigPaths()
+	if err != nil {
+		return fmt.Errorf("failed to schedule the revert: %w", err)
+	}
+	err = state.UpdateDefault(func(s *state.State) (bool, error) {
+		s.AddRevert(state.Revert{Context: name, Namespace: newNS, Previous: previous, At: time.Now().Add(o.revertAfter), Kubeconfig: paths})
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to schedule the revert: %w", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to schedule the revert: %w", err)
+	}
+	child := exec.Command(executable, "revert-pending")
+	detach(child)
+	if err := child.Start(); err != nil {
+		fmt.Fprintf(o.ErrOut, "warning: failed to start the background revert, it is applied by the next kubectl ns call: %v\n", err)
+		return nil
+	}
+	fmt.Fprintf(o.ErrOut, "the namespace is reverted to \"%s\" in %s\n", previous, o.revertAfter)
+	return child.Process.Release()
+}
+
+// applyDueReverts reverts all temporary switches which are due, a message
+// per revert is written to w. A revert is only removed from the state once
+// it succeeded, a failed one is retried by the next kubectl ns call.
+func (o *NsOptions) applyDueReverts(w io.Writer) error {
+	s, err := state.LoadDefault()
+	if err != nil {
+		return fmt.Errorf("failed to load state: %w", err)
+	}
+	due := s.DueReverts(time.Now())
+	if len(due) == 0 {
+		return nil
+	}
+
+	// reverts are applied before the configuration is loaded, the hooks
+	// need it
+	if o.config == nil {
+		if o.config, err = config.LoadDefault(); err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	for _, r := range due {
+		if err := o.revertNamespace(r, w); err != nil {
+			return err
+		}
+		err := state.UpdateDefault(func(s *state.State) (bool, error) {
+			return s.RemoveRevert(r), nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save state: %w", err)
+		}
+	}
+	return nil
+}
+
+// revertNamespace restores the previous namespace of the context if the
+// temporary namespace is still set. The kubeconfig files of the switch are
+// read again as they may have changed since the switch.
+func (o *NsOptions) revertNamespace(r state.Revert, w io.Writer) error {
+	if len(r.Kubeconfig) > 0 {
+		defer func(paths []string, backedUp bool) {
+			o.kubeconfigs, o.backedUp = paths, backedUp
+		}(o.kubeconfigs, o.backedUp)
+		o.kubeconfigs, o.backedUp = r.Kubeconfig, false
+	}
+
+	config, err := o.startingKubeconfig()
+	if err != nil {
+		return err
+	}
+	ctx, ok := config.Contexts[r.Context]
+	if !ok || ctx.Namespace != r.Namespace {
+		return nil
+	}
+
+	event := switchEvent{FromContext: r.Context, ToContext: r.Context, FromNamespace: r.Namespace, ToNamespace: r.Previous}
+	if err := o.preSwitch(event, w); err != nil {
+		return err
+	}
+
+	ctx.Namespace = r.Previous
+	if err := o.modifyKubeconfig(*config); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "namespace of context \"%s\" reverted to \"%s\"\n", r.Context, r.Previous)
+
+	if err := o.rememberSwitch(r.Context, r.Namespace, r.Previous); err != nil {
+		fmt.Fprintf(w, "warning: failed to save previous namespace: %v\n", err)
+	}
+	if err := o.recordHistory(r.Context, r.Namespace, r.Previous, ""); err != nil {
+		fmt.Fprintf(w, "warning: failed to record history: %v\n", err)
+	}
+	o.postSwitch(event, w)
+	return nil
+}
+
+// absoluteKubeconfigPaths returns the kubeconfig files of the invocation,
+// a revert applied later from another directory finds them as well
+func (o *NsOptions) absoluteKubeconfigPaths() ([]string, error) {
+	paths := []string{}
+	for _, path := range o.kubeconfigPaths() {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, abs)
+	}
+	return paths, nil
+}
+
+// shellCommand returns the shell of the user
+func shellCommand() []string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return []string{shell}
+	}
+	if runtime.GOOS == "windows" {
+		if shell := os.Getenv("COMSPEC"); shell != "" {
+			return []string{shell}
+		}
+		return []string{"cmd.exe"}
+	}
+	return []string{"/bin/sh"}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/postfinance/kubectl-ns/pkg/state"
+)
+
+// kubeconfigState reports whether the state of the contexts is stored in
+// the kubeconfig as well
+func (o *NsOptions) kubeconfigState() bool {
+	return o.config != nil && o.config.KubeconfigState != nil && *o.config.KubeconfigState
+}
+
+// loadState reads the local state, with kubeconfigState enabled the state
+// stored in the contexts of the kubeconfig is merged in
+func (o *NsOptions) loadState() (*state.State, error) {
+	s, err := state.LoadDefault()
+	if err != nil || !o.kubeconfigState() {
+		return s, err
+	}
+
+	for name, ctx := range o.rawConfig.Contexts {
+		c, ok, err := state.FromContext(ctx)
+		if err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: ignoring the state of context \"%s\": %v\n", name, err)
+			continue
+		}
+		if ok {
+			s.Merge(name, c)
+		}
+	}
+	return s, nil
+}
+
+// updateState applies fn to the state while holding its lock, so a
+// concurrent kubectl ns like the background revert of --for can't drop the
+// change. The state is only saved if fn reports a change.
+func (o *NsOptions) updateState(fn func(*state.State) (bool, error)) error {
+	path, err := state.DefaultPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := state.Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := o.loadState()
+	if err != nil {
+		return err
+	}
+	changed, err := fn(s)
+	if err != nil || !changed {
+		return err
+	}
+	return o.saveState(s)
+}
+
+// saveState writes the local state, with kubeconfigState enabled the state
+// of every context is written to its extensions in the kubeconfig as well.
+// The local state is kept if the kubeconfig can't be written.
+func (o *NsOptions) saveState(s *state.State) error {
+	if err := s.SaveDefault(); err != nil {
+		return err
+	}
+	if !o.kubeconfigState() || o.inCluster {
+		return nil
+	}
+
+	config, err := o.startingKubeconfig()
+	if err != nil {
+		return fmt.Errorf("failed to store the state in the kubeconfig: %w", err)
+	}
+	changed := false
+	for name, ctx := range config.Contexts {
+		ok, err := s.ContextState(name).ToContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to store the state of context \"%s\" in the kubeconfig: %w", name, err)
+		}
+		changed = changed || ok
+	}
+	if !changed {
+		return nil
+	}
+	if err := o.modifyKubeconfig(*config); err != nil {
+		return fmt.Errorf("failed to store the state in the kubeconfig: %w", err)
+	}
+	// later writes of the loaded kubeconfig must not drop the new state
+	for name, ctx := range config.Contexts {
+		if loaded, ok := o.rawConfig.Contexts[name]; ok {
+			loaded.Extensions = ctx.Extensions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// finalizeNamespaceSpec is the change of the namespace sent to the
+	// finalize subresource
+	finalizeNamespaceSpec = `{"spec":{"finalizers":[]}}`
+)
+
+// blockingHints explains the deletion conditions of a namespace which
+// block its removal while their status is True
+var blockingHints = map[v1.NamespaceConditionType]string{
+	v1.NamespaceDeletionDiscoveryFailure: "an API service is unavailable, the namespace controller can't tell which resources remain, fix or remove the API service (kubectl get apiservices)",
+	v1.NamespaceDeletionGVParsingFailure: "the group version of an API service can't be parsed, fix or remove the API service (kubectl get apiservices)",
+	v1.NamespaceDeletionContentFailure:   "deleting some resources failed, check the remaining resources and the permissions of the namespace controller",
+	v1.NamespaceContentRemaining:         "resources are still being deleted, they are listed as remaining",
+	v1.NamespaceFinalizersRemaining:      "remaining resources have finalizers, the controllers owning them have to remove them or are gone",
+}
+
+// remainingFinalizer matches a finalizer in the message of the
+// NamespaceFinalizersRemaining condition, e.g. "example.com/widget-protection
+// in 2 resource instances"
+var remainingFinalizer = regexp.MustCompile(`([^\s,:]+) in \d+ resource instances`)
+
+var (
+	stuckExample = `
+	# report every namespace stuck in Terminating
+	kubectl ns stuck
+
+	# report what blocks the deletion of the namespace foo
+	kubectl ns stuck foo
+
+	# show how the deletion of the namespace foo would be unblocked
+	kubectl ns stuck foo --fix --dry-run`
+)
+
+// fixStep is a change unblocking the deletion of a namespace
+type fixStep struct {
+	// target is the patched object, e.g. configmaps "foo"
+	target     string
+	request    string
+	finalizers []string
+	apply      func() error
+}
+
+// StuckOptions provides information required to report namespaces stuck in
+// Terminating
+type StuckOptions struct {
+	ns   *NsOptions
+	name string
+
+	fix bool
+	yes bool
+}
+
+// NewStuckCmd provides a cobra command reporting what blocks the deletion
+// of terminating namespaces
+func NewStuckCmd(ns *NsOptions) *cobra.Command {
+	opt := &StuckOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "stuck [namespace]",
+		Short:        "Report what blocks the deletion of namespaces stuck in Terminating",
+		Example:      stuckExample,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.fix, "fix", false, "remove the orphaned finalizers blocking the deletion of the namespace after confirmation, --dry-run shows the patches")
+	cmd.Flags().BoolVarP(&opt.yes, "yes", "y", false, "apply the fixes without confirmation")
+
+	return cmd
+}
+
+// Complete sets all information required for the report, all terminating
+// namespaces are reported if none is given
+func (o *StuckOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		o.name = args[0]
+		if target, ok := o.ns.config.Aliases[o.name]; ok {
+			o.name = target
+		}
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *StuckOptions) Validate() error {
+	if o.yes && !o.fix {
+		return fmt.Errorf("--yes can only be used with --fix")
+	}
+	if o.fix && o.name == "" {
+		return fmt.Errorf("--fix requires a namespace")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run prints the finalizers, the failed deletion conditions and the
+// remaining resources of the terminating namespaces
+func (o *StuckOptions) Run() error {
+	namespaces, err := o.terminatingNamespaces()
+	if err != nil {
+		return err
+	}
+	if len(namespaces) == 0 {
+		if o.name != "" {
+			fmt.Fprintf(o.ns.ErrOut, "namespace \"%s\" is not terminating\n", o.name)
+		} else {
+			fmt.Fprintln(o.ns.ErrOut, "no namespace is terminating")
+		}
+		return nil
+	}
+
+	for i, ns := range namespaces {
+		if i > 0 {
+			fmt.Fprintln(o.ns.Out)
+		}
+		inventory, err := o.ns.namespaceInventory(ns.GetName())
+		if err != nil {
+			return fmt.Errorf("failed to list resources of namespace \"%s\": %w", ns.GetName(), err)
+		}
+		if err := printBlockers(o.ns.Out, ns, inventory); err != nil {
+			return err
+		}
+		if o.fix {
+			if err := o.fixNamespace(ns, inventory); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fixNamespace removes the orphaned finalizers of the remaining resources.
+// If none are left and the namespace controller can't discover all
+// resources, the namespace is finalized which removes its own finalizers.
+// Everything else is left to the namespace controller.
+func (o *StuckOptions) fixNamespace(ns v1.Namespace, inventory []inventoryItem) error {
+	steps, err := o.fixSteps(ns, inventory)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(o.ns.Out)
+	if len(steps) == 0 {
+		fmt.Fprintf(o.ns.Out, "nothing to fix in namespace \"%s\", no orphaned finalizers found\n", ns.GetName())
+		return nil
+	}
+
+	verb := "will"
+	if o.ns.dryRun {
+		verb = "would"
+	}
+	fmt.Fprintf(o.ns.Out, "the following patches %s be applied to unblock namespace \"%s\":\n", verb, ns.GetName())
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	for _, step := range steps {
+		fmt.Fprintf(w, "  %s\t%s\tremoves %s\n", step.target, step.request, strings.Join(step.finalizers, ","))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if o.ns.dryRun {
+		return nil
+	}
+
+	if !o.yes {
+		ok, err := o.ns.confirm(fmt.Sprintf("remove the finalizers of namespace \"%s\"? resources of removed finalizers may not be cleaned up", ns.GetName()))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errAborted
+		}
+	}
+
+	for _, step := range steps {
+		err := o.ns.withRetry(step.apply)
+		if apierrors.IsInvalid(err) || apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to patch %s, it changed since it was listed, run the command again: %w", step.target, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to patch %s: %w", step.target, err)
+		}
+		fmt.Fprintf(o.ns.Out, "%s patched\n", step.target)
+	}
+	return nil
+}
+
+// fixSteps returns the patches unblocking the deletion of the namespace.
+// Only the finalizers the namespace controller reports as remaining are
+// removed, finalizers of the control plane are never orphaned. The patches
+// fail if an object changed since it was listed.
+func (o *StuckOptions) fixSteps(ns v1.Namespace, inventory []inventoryItem) ([]fixStep, error) {
+	steps := []fixStep{}
+	dyn, err := o.ns.dynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	orphaned := orphanedFinalizers(ns)
+	for _, item := range inventory {
+		resource := dyn.Resource(item.Resource).Namespace(ns.GetName())
+		for _, obj := range item.Objects {
+			kept, removed := []string{}, []string{}
+			for _, f := range obj.GetFinalizers() {
+				if orphaned[f] {
+					removed = append(removed, f)
+				} else {
+					kept = append(kept, f)
+				}
+			}
+			if len(removed) == 0 {
+				continue
+			}
+			patch, err := json.Marshal([]map[string]interface{}{
+				{"op": "test", "path": "/metadata/resourceVersion", "value": obj.GetResourceVersion()},
+				{"op": "replace", "path": "/metadata/finalizers", "value": kept},
+			})
+			if err != nil {
+				return nil, err
+			}
+			name := obj.GetName()
+			steps = append(steps, fixStep{
+				target:     fmt.Sprintf("%s \"%s\"", item.Name(), name),
+				request:    "PATCH " + string(patch),
+				finalizers: removed,
+				apply: func() error {
+					_, err := resource.Patch(o.ns.ctx, name, types.JSONPatchType, patch, metav1.PatchOptions{})
+					return err
+				},
+			})
+		}
+	}
+	if len(steps) > 0 || len(ns.Spec.Finalizers) == 0 || !conditionTrue(ns, v1.NamespaceDeletionDiscoveryFailure, v1.NamespaceDeletionGVParsingFailure) {
+		return steps, nil
+	}
+
+	clientset, err := o.ns.client()
+	if err != nil {
+		return nil, err
+	}
+	finalized := ns.DeepCopy()
+	finalized.Spec.Finalizers = nil
+	steps = append(steps, fixStep{
+		target:     fmt.Sprintf("namespaces \"%s\"", ns.GetName()),
+		request:    "PUT finalize " + finalizeNamespaceSpec,
+		finalizers: finalizerNames(ns.Spec.Finalizers),
+		apply: func() error {
+			_, err := clientset.CoreV1().Namespaces().Finalize(o.ns.ctx, finalized, metav1.UpdateOptions{})
+			return err
+		},
+	})
+	return steps, nil
+}
+
+// terminatingNamespaces returns the given namespace if it is terminating,
+// or all terminating namespaces. The cached list is bypassed as the phases
+// change while namespaces are deleted.
+func (o *StuckOptions) terminatingNamespaces() ([]v1.Namespace, error) {
+	if o.name != "" {
+		clientset, err := o.ns.client()
+		if err != nil {
+			return nil, err
+		}
+		var ns *v1.Namespace
+		err = o.ns.withRetry(func() (err error) {
+			ns, err = clientset.CoreV1().Namespaces().Get(o.ns.ctx, o.name, metav1.GetOptions{})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace: %w", err)
+		}
+		if ns.Status.Phase != v1.NamespaceTerminating {
+			return nil, nil
+		}
+		return []v1.Namespace{*ns}, nil
+	}
+
+	o.ns.refresh = true
+	if err := o.ns.listNamespaces(); err != nil {
+		return nil, err
+	}
+	namespaces := []v1.Namespace{}
+	for _, ns := range o.ns.namespaces.Items {
+		if ns.Status.Phase == v1.NamespaceTerminating {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces, nil
+}
+
+// printBlockers prints what blocks the deletion of a terminating namespace:
+// the conditions reporting failures or remaining content with a hint, the
+// finalizers of the namespace and the resources which are left
+func printBlockers(out io.Writer, ns v1.Namespace, inventory []inventoryItem) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", ns.GetName())
+	if t := ns.GetDeletionTimestamp(); t != nil {
+		fmt.Fprintf(w, "Terminating:\t%s\n", age(t.Time))
+	}
+	fmt.Fprintf(w, "Finalizers:\t%s\n", listOrNone(finalizerNames(ns.Spec.Finalizers)))
+
+	blocked := false
+	for _, c := range ns.Status.Conditions {
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+		if !blocked {
+			fmt.Fprintln(w, "Blocked by:")
+			blocked = true
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", c.Type, strings.TrimSpace(c.Message))
+		if hint, ok := blockingHints[c.Type]; ok {
+			fmt.Fprintf(w, "  \thint: %s\n", hint)
+		}
+	}
+	if !blocked {
+		fmt.Fprintln(w, "Blocked by:\t<none>, the namespace controller has not reported a reason yet")
+	}
+
+	if len(inventory) == 0 {
+		fmt.Fprintln(w, "Remaining:\t<none>")
+		return w.Flush()
+	}
+	fmt.Fprintln(w, "Remaining:")
+	fmt.Fprintln(w, "  Resource\tName\tFinalizers")
+	for _, item := range inventory {
+		for _, obj := range item.Objects {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", item.Name(), obj.GetName(), listOrNone(obj.GetFinalizers()))
+		}
+	}
+	return w.Flush()
+}
+
+// orphanedFinalizers returns the finalizers the namespace controller is
+// waiting for according to the NamespaceFinalizersRemaining condition.
+// Finalizers of the control plane like kubernetes.io/pvc-protection or
+// foregroundDeletion are removed by controllers which are always running.
+func orphanedFinalizers(ns v1.Namespace) map[string]bool {
+	orphaned := map[string]bool{}
+	for _, c := range ns.Status.Conditions {
+		if c.Type != v1.NamespaceFinalizersRemaining || c.Status != v1.ConditionTrue {
+			continue
+		}
+		for _, m := range remainingFinalizer.FindAllStringSubmatch(c.Message, -1) {
+			domain := strings.SplitN(m[1], "/", 2)[0]
+			if !strings.Contains(m[1], "/") || domain == "kubernetes.io" || strings.HasSuffix(domain, ".kubernetes.io") {
+				continue
+			}
+			orphaned[m[1]] = true
+		}
+	}
+	return orphaned
+}
+
+// conditionTrue reports whether one of the conditions of the namespace is
+// True
+func conditionTrue(ns v1.Namespace, conditions ...v1.NamespaceConditionType) bool {
+	for _, c := range ns.Status.Conditions {
+		for _, t := range conditions {
+			if c.Type == t && c.Status == v1.ConditionTrue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// finalizerNames converts the finalizers of a namespace spec to strings
+func finalizerNames(finalizers []v1.FinalizerName) []string {
+	names := make([]string, 0, len(finalizers))
+	for _, f := range finalizers {
+		names = append(names, string(f))
+	}
+	return names
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/postfinance/kubectl-ns/pkg/config"
+	v1 "k8s.io/api/core/v1"
+)
+
+// colorSequence matches the escape sequences of colors
+var colorSequence = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+var colorAttributes = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// namespaceStyle returns the style of a namespace in the namespace list.
+// The color of a matching label style takes precedence over the color of
+// the current namespace, bold and prefix of both styles are combined.
+func (o *NsOptions) namespaceStyle(ns v1.Namespace, current bool) config.Style {
+	theme := o.config.Theme
+	style, _ := theme.LabelStyle(ns.GetLabels())
+	if !current {
+		return style
+	}
+
+	if style.Color == "" {
+		style.Color = theme.Current.Color
+	}
+	if style.Color == "" {
+		style.Color = "red"
+	}
+	style.Bold = style.Bold || theme.Current.Bold
+	style.Prefix = theme.Current.Prefix + style.Prefix
+	return style
+}
+
+// sprintStyled renders name with the style, colors are omitted if they are
+// disabled
+func sprintStyled(style config.Style, name string) string {
+	attrs := []color.Attribute{}
+	if a, ok := colorAttributes[style.Color]; ok {
+		attrs = append(attrs, a)
+	}
+	if style.Bold {
+		attrs = append(attrs, color.Bold)
+	}
+	if len(attrs) == 0 {
+		return style.Prefix + name
+	}
+	return style.Prefix + color.New(attrs...).Sprint(name)
+}
+
+// visibleWidth returns the number of characters s takes on the terminal,
+// color escape sequences take none
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(colorSequence.ReplaceAllString(s, ""))
+}
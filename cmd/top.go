@@ -0,0 +1,309 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sort keys of top
+const (
+	topSortCPU    = "cpu"
+	topSortMemory = "memory"
+	topSortPods   = "pods"
+)
+
+// topSortKeys lists the supported sort keys of top
+var topSortKeys = []string{topSortCPU, topSortMemory, topSortPods}
+
+var (
+	topExample = `
+	# rank the namespaces by the CPU usage of their pods
+	kubectl ns top
+
+	# rank the namespaces by the memory requested by their pods
+	kubectl ns top --requests --sort-by memory
+
+	# show the ten namespaces with the most pods
+	kubectl ns top --sort-by pods --head 10`
+)
+
+// resourceRequests are the summed requests and limits of the containers of
+// pods
+type resourceRequests struct {
+	pods           int
+	cpuRequests    resource.Quantity
+	cpuLimits      resource.Quantity
+	memoryRequests resource.Quantity
+	memoryLimits   resource.Quantity
+}
+
+// add sums the requests and limits of the containers of a pod
+func (r *resourceRequests) add(pod v1.Pod) {
+	r.pods++
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Requests[v1.ResourceCPU]; ok {
+			r.cpuRequests.Add(q)
+		}
+		if q, ok := c.Resources.Limits[v1.ResourceCPU]; ok {
+			r.cpuLimits.Add(q)
+		}
+		if q, ok := c.Resources.Requests[v1.ResourceMemory]; ok {
+			r.memoryRequests.Add(q)
+		}
+		if q, ok := c.Resources.Limits[v1.ResourceMemory]; ok {
+			r.memoryLimits.Add(q)
+		}
+	}
+}
+
+// topRow is a ranked namespace, cpu and memory are the values sorted by
+type topRow struct {
+	name   string
+	pods   int
+	cpu    resource.Quantity
+	memory resource.Quantity
+	cells  []string
+}
+
+// TopOptions provides information required to rank the namespaces by their
+// resource consumption
+type TopOptions struct {
+	ns *NsOptions
+
+	sortBy   string
+	requests bool
+	head     int
+}
+
+// NewTopCmd provides a cobra command ranking the namespaces by the usage or
+// the requests of their pods
+func NewTopCmd(ns *NsOptions) *cobra.Command {
+	opt := &TopOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "top",
+		Short:        "Rank the namespaces by the resource consumption of their pods",
+		Example:      topExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&opt.sortBy, "sort-by", topSortCPU, "sort key, one of: "+strings.Join(topSortKeys, "|"))
+	cmd.Flags().BoolVar(&opt.requests, "requests", false, "rank by the requests and limits of the pods instead of the usage reported by metrics-server")
+	cmd.Flags().IntVar(&opt.head, "head", 0, "only show this number of namespaces, 0 shows all")
+
+	return cmd
+}
+
+// Complete sets all information required for ranking the namespaces
+func (o *TopOptions) Complete(cmd *cobra.Command, args []string) error {
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	// namespaces may be requested one by one like the details of wide
+	// output
+	if o.ns.qps == 0 && o.ns.burst == 0 {
+		o.ns.qps, o.ns.burst = enrichQPS, enrichBurst
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *TopOptions) Validate() error {
+	if !containsString(topSortKeys, o.sortBy) {
+		return fmt.Errorf("invalid sort key \"%s\", use one of %s", o.sortBy, strings.Join(topSortKeys, ", "))
+	}
+	if o.head < 0 {
+		return fmt.Errorf("--head must not be negative")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run ranks the namespaces by the live usage of their pods, or by their
+// requests if metrics-server is not available or --requests is set
+func (o *TopOptions) Run() error {
+	if err := o.ns.listNamespaces(); err != nil {
+		return err
+	}
+	namespaces := o.ns.namespaces.Items
+
+	var header []string
+	var rows []topRow
+	var err error
+	if !o.requests {
+		header, rows, err = o.usageRows(namespaces)
+		if err != nil {
+			return err
+		}
+	}
+	if rows == nil {
+		if header, rows, err = o.requestRows(namespaces); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		switch o.sortBy {
+		case topSortMemory:
+			if c := a.memory.Cmp(b.memory); c != 0 {
+				return c > 0
+			}
+		case topSortPods:
+			if a.pods != b.pods {
+				return a.pods > b.pods
+			}
+		default:
+			if c := a.cpu.Cmp(b.cpu); c != 0 {
+				return c > 0
+			}
+		}
+		return a.name < b.name
+	})
+	if o.head > 0 && len(rows) > o.head {
+		rows = rows[:o.head]
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(append([]string{row.name, strconv.Itoa(row.pods)}, row.cells...), "\t"))
+	}
+	return w.Flush()
+}
+
+// usageRows returns the rows of the live usage, rows is nil if the metrics
+// API is not available
+func (o *TopOptions) usageRows(namespaces []v1.Namespace) ([]string, []topRow, error) {
+	usage, found, err := o.ns.namespaceUsage(namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		fmt.Fprintf(o.ns.ErrOut, "warning: the metrics API %s is not available, the namespaces are ranked by the requests of their pods\n", metricsGroup)
+		return nil, nil, nil
+	}
+
+	rows := []topRow{}
+	for name, u := range usage {
+		rows = append(rows, topRow{
+			name:   name,
+			pods:   u.pods,
+			cpu:    u.cpu,
+			memory: u.memory,
+			cells:  []string{formatCPU(u.cpu), formatMemory(u.memory)},
+		})
+	}
+	return []string{"NAMESPACE", "PODS", "CPU", "MEMORY"}, rows, nil
+}
+
+// requestRows returns the rows of the requests and limits of the pods
+func (o *TopOptions) requestRows(namespaces []v1.Namespace) ([]string, []topRow, error) {
+	requests, err := o.ns.namespaceRequests(namespaces)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := []topRow{}
+	for name, r := range requests {
+		rows = append(rows, topRow{
+			name:   name,
+			pods:   r.pods,
+			cpu:    r.cpuRequests,
+			memory: r.memoryRequests,
+			cells: []string{
+				formatCPU(r.cpuRequests), formatCPU(r.cpuLimits),
+				formatMemory(r.memoryRequests), formatMemory(r.memoryLimits),
+			},
+		})
+	}
+	return []string{"NAMESPACE", "PODS", "CPU REQUESTS", "CPU LIMITS", "MEMORY REQUESTS", "MEMORY LIMITS"}, rows, nil
+}
+
+// namespaceRequests sums the requests and limits of the running and
+// pending pods by namespace. The pods of all namespaces are listed at once,
+// if this is forbidden every namespace is listed on its own.
+func (o *NsOptions) namespaceRequests(namespaces []v1.Namespace) (map[string]*resourceRequests, error) {
+	clientset, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+
+	requests := map[string]*resourceRequests{}
+	for _, ns := range namespaces {
+		requests[ns.GetName()] = &resourceRequests{}
+	}
+	add := func(pods []v1.Pod) {
+		for _, pod := range pods {
+			r, ok := requests[pod.GetNamespace()]
+			if !ok || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+				continue
+			}
+			r.add(pod)
+		}
+	}
+
+	opts := metav1.ListOptions{Limit: o.chunkSize}
+	for {
+		var pods *v1.PodList
+		err := o.withRetry(func() (err error) {
+			pods, err = clientset.CoreV1().Pods("").List(o.ctx, opts)
+			return err
+		})
+		if apierrors.IsForbidden(err) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pods: %w", err)
+		}
+		add(pods.Items)
+		if pods.Continue == "" {
+			return requests, nil
+		}
+		opts.Continue = pods.Continue
+	}
+
+	lists := make([]*v1.PodList, len(namespaces))
+	forEachNamespace(namespaces, func(i int, ns v1.Namespace) {
+		_ = o.withRetry(func() (err error) {
+			lists[i], err = clientset.CoreV1().Pods(ns.GetName()).List(o.ctx, metav1.ListOptions{})
+			return err
+		})
+	})
+	for i, ns := range namespaces {
+		// namespaces without permissions have no requests
+		if lists[i] == nil {
+			delete(requests, ns.GetName())
+			continue
+		}
+		add(lists[i].Items)
+	}
+	return requests, nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/postfinance/kubectl-ns/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoExample = `
+	# revert the last namespace switch
+	kubectl ns undo
+
+	# every call reverts the switch before the one undone last
+	kubectl ns undo && kubectl ns undo`
+)
+
+// UndoOptions provides information required to revert the last namespace
+// switch
+type UndoOptions struct {
+	ns    *NsOptions
+	store *history.Store
+}
+
+// NewUndoCmd provides a cobra command reverting the last namespace switch
+func NewUndoCmd(ns *NsOptions) *cobra.Command {
+	opt := &UndoOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "undo",
+		Short:        "Revert the last namespace switch",
+		Example:      undoExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// Complete sets all information required for reverting the switch
+func (o *UndoOptions) Complete(cmd *cobra.Command, args []string) error {
+	var err error
+	if o.store, err = history.NewDefaultStore(); err != nil {
+		return err
+	}
+
+	return o.ns.loadConfig()
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *UndoOptions) Validate() error {
+	return nil
+}
+
+// Run reverts the most recent switch of the history which was not undone
+// yet. The namespace and context must still be the ones set by the switch,
+// changes made in the meantime are not overwritten.
+func (o *UndoOptions) Run() error {
+	entries, err := o.store.List()
+	if err != nil {
+		return fmt.Errorf("failed to read history: %w", err)
+	}
+	i := len(entries) - 1
+	for i >= 0 && entries[i].Undone {
+		i--
+	}
+	if i < 0 {
+		return fmt.Errorf("nothing to undo")
+	}
+	entry := entries[i]
+
+	raw := o.ns.rawConfig
+	ctx, ok := raw.Contexts[entry.Context]
+	if !ok {
+		return fmt.Errorf("context \"%s\" of the last switch does not exist anymore", entry.Context)
+	}
+	current := ctx.Namespace
+	if current == "" {
+		current = "default"
+	}
+	if current != entry.To {
+		return fmt.Errorf("namespace of context \"%s\" was changed to \"%s\" since the last switch, nothing to undo", entry.Context, current)
+	}
+
+	event := switchEvent{
+		FromContext:   raw.CurrentContext,
+		ToContext:     raw.CurrentContext,
+		FromNamespace: entry.To,
+		ToNamespace:   entry.From,
+	}
+	if entry.PreviousContext != "" {
+		if raw.CurrentContext != entry.Context {
+			return fmt.Errorf("current context was changed to \"%s\" since the last switch, nothing to undo", raw.CurrentContext)
+		}
+		previous, ok := raw.Contexts[entry.PreviousContext]
+		if !ok {
+			return fmt.Errorf("previous context \"%s\" does not exist anymore", entry.PreviousContext)
+		}
+		event.ToContext = entry.PreviousContext
+		event.ToNamespace = previous.Namespace
+		if event.ToNamespace == "" {
+			event.ToNamespace = "default"
+		}
+	}
+	if !o.ns.dryRun {
+		if err := o.ns.preSwitch(event, o.ns.ErrOut); err != nil {
+			return err
+		}
+	}
+
+	ctx.Namespace = entry.From
+	if entry.PreviousContext != "" {
+		raw.CurrentContext = entry.PreviousContext
+	}
+	if err := o.ns.modifyKubeconfig(raw); err != nil {
+		return err
+	}
+	if o.ns.dryRun {
+		return nil
+	}
+
+	msg := fmt.Sprintf("namespace of context \"%s\" restored to \"%s\"", entry.Context, entry.From)
+	if entry.PreviousContext != "" {
+		msg = fmt.Sprintf("context restored to \"%s\", %s", entry.PreviousContext, msg)
+	}
+	fmt.Fprintln(o.ns.Out, msg)
+
+	undone := entry
+	undone.Undone = true
+	if err := o.store.Replace(entry, undone); err != nil {
+		fmt.Fprintf(o.ns.ErrOut, "warning: failed to record history: %v\n", err)
+	}
+	if err := o.ns.rememberSwitch(entry.Context, entry.To, entry.From); err != nil {
+		fmt.Fprintf(o.ns.ErrOut, "warning: failed to save previous namespace: %v\n", err)
+	}
+	o.ns.postSwitch(event, o.ns.ErrOut)
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/postfinance/kubectl-ns/pkg/release"
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeExample = `
+	# check whether a newer release is available
+	kubectl ns upgrade --check-only
+
+	# replace the plugin with the latest release
+	kubectl ns upgrade`
+)
+
+// UpgradeOptions provides information required to upgrade the plugin
+type UpgradeOptions struct {
+	ns     *NsOptions
+	client *release.Client
+
+	executable string
+	checkOnly  bool
+}
+
+// NewUpgradeCmd provides a cobra command replacing the plugin binary with
+// the latest release
+func NewUpgradeCmd(ns *NsOptions) *cobra.Command {
+	opt := &UpgradeOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "upgrade",
+		Short:        "Upgrade the plugin to the latest release",
+		Example:      upgradeExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&opt.checkOnly, "check-only", false, "only check whether a newer release is available")
+
+	return cmd
+}
+
+// Complete sets all information required for upgrading the plugin
+func (o *UpgradeOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.client = release.NewDefaultClient()
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the plugin binary: %w", err)
+	}
+	if o.executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("failed to find the plugin binary: %w", err)
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *UpgradeOptions) Validate() error {
+	if !o.checkOnly && installedByKrew(o.executable) {
+		return fmt.Errorf("the plugin is installed by krew, upgrade it with kubectl krew upgrade ns")
+	}
+
+	return nil
+}
+
+// Run checks the latest release and replaces the running binary with it if
+// it is newer. The checksum of the archive is compared with the checksums of
+// the release before the binary is written.
+func (o *UpgradeOptions) Run() error {
+	latest, err := o.client.Latest(o.ns.ctx)
+	if err != nil {
+		return err
+	}
+	if !release.Newer(latest.TagName, version) {
+		fmt.Fprintf(o.ns.Out, "kubectl ns %s is up to date\n", version)
+		return nil
+	}
+	if o.checkOnly {
+		fmt.Fprintf(o.ns.Out, "kubectl ns %s is available, %s is installed: %s\n", latest.TagName, version, latest.HTMLURL)
+		return nil
+	}
+
+	binary, err := o.client.Binary(o.ns.ctx, latest, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+	if o.ns.dryRun {
+		fmt.Fprintf(o.ns.Out, "%s would be upgraded from %s to %s\n", o.executable, version, latest.TagName)
+		return nil
+	}
+	if err := replaceExecutable(o.executable, binary); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", o.executable, err)
+	}
+	fmt.Fprintf(o.ns.Out, "kubectl ns upgraded from %s to %s\n", version, latest.TagName)
+	return nil
+}
+
+// installedByKrew reports whether the binary is managed by krew
+func installedByKrew(executable string) bool {
+	if root := os.Getenv("KREW_ROOT"); root != "" && strings.HasPrefix(executable, filepath.Clean(root)+string(filepath.Separator)) {
+		return true
+	}
+	return strings.Contains(filepath.ToSlash(executable), "/.krew/")
+}
+
+// replaceExecutable atomically replaces the binary at path. Windows doesn't
+// allow to replace a running binary, it is moved aside first and moved back
+// if the new binary can't be written.
+func replaceExecutable(path string, binary []byte) error {
+	if runtime.GOOS != "windows" {
+		return safefile.WriteFile(path, binary, 0755)
+	}
+
+	old := path + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return err
+	}
+	if err := safefile.WriteFile(path, binary, 0755); err != nil {
+		if restoreErr := os.Rename(old, path); restoreErr != nil {
+			return fmt.Errorf("%v, the previous binary is left at %s: %w", err, old, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
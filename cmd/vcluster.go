@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/postfinance/kubectl-ns/pkg/cache"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// vclusterSelector selects the StatefulSets and Deployments running the
+	// control plane of a vcluster
+	vclusterSelector = "app=vcluster"
+	// labelVClusterRelease holds the name of the vcluster
+	labelVClusterRelease = "release"
+)
+
+// vclusterHosts returns the names of the vclusters per host namespace, they
+// are found by the well-known labels of the vcluster control plane which
+// runs either as StatefulSet or as Deployment
+func (o *NsOptions) vclusterHosts() (map[string][]string, error) {
+	clientset, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+	opts := metav1.ListOptions{LabelSelector: vclusterSelector}
+
+	hosts := map[string][]string{}
+	add := func(meta metav1.ObjectMeta) {
+		name := meta.GetLabels()[labelVClusterRelease]
+		if name == "" {
+			name = meta.GetName()
+		}
+		hosts[meta.GetNamespace()] = append(hosts[meta.GetNamespace()], name)
+	}
+
+	err = o.withRetry(func() error {
+		statefulSets, err := clientset.AppsV1().StatefulSets(metav1.NamespaceAll).List(o.ctx, opts)
+		if err != nil {
+			return err
+		}
+		deployments, err := clientset.AppsV1().Deployments(metav1.NamespaceAll).List(o.ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		hosts = map[string][]string{}
+		for _, s := range statefulSets.Items {
+			add(s.ObjectMeta)
+		}
+		for _, d := range deployments.Items {
+			add(d.ObjectMeta)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find vclusters: %w", err)
+	}
+
+	for _, names := range hosts {
+		sort.Strings(names)
+	}
+	return hosts, nil
+}
+
+// markVClusters looks up the vclusters marked in the listing. They are
+// cached like the namespace list as two cluster wide lists are required.
+// Without permissions no namespace is marked.
+func (o *NsOptions) markVClusters() {
+	if o.hostedVClusters != nil {
+		return
+	}
+
+	var c *cache.Cache
+	if o.cacheTTL > 0 {
+		var err error
+		if c, err = cache.New(o.cacheTTL); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+			c = nil
+		}
+	}
+	if c != nil && !o.refresh {
+		if v, err := c.LoadVClusters(o.cacheKey()); err == nil && v != nil {
+			o.hostedVClusters = v.Hosts
+			return
+		}
+	}
+
+	hosts, err := o.vclusterHosts()
+	switch {
+	case apierrors.IsForbidden(err):
+		hosts = map[string][]string{}
+	case err != nil:
+		fmt.Fprintf(o.ErrOut, "warning: %v\n", err)
+		return
+	}
+	o.hostedVClusters = hosts
+	if c != nil {
+		if err := c.SetVClusters(o.cacheKey(), hosts); err != nil {
+			fmt.Fprintf(o.ErrOut, "warning: failed to update namespace cache: %v\n", err)
+		}
+	}
+}
+
+// filterVClusters removes all namespaces which don't host a vcluster, the
+// vclusters are kept to mark the namespaces in the listing
+func (o *NsOptions) filterVClusters() error {
+	hosts, err := o.vclusterHosts()
+	if err != nil {
+		return err
+	}
+	o.hostedVClusters = hosts
+
+	result := make([]v1.Namespace, 0, len(o.namespaces.Items))
+	for _, ns := range o.namespaces.Items {
+		if len(hosts[ns.GetName()]) > 0 {
+			result = append(result, ns)
+		}
+	}
+	o.namespaces.Items = result
+	return nil
+}
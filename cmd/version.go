@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// build information, set by the linker with -X, see the README
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+// clientGoModule is the module path of client-go
+const clientGoModule = "k8s.io/client-go"
+
+var (
+	versionExample = `
+	# print the version and build information
+	kubectl ns version
+
+	# print the build information as JSON
+	kubectl ns version -o json`
+)
+
+// BuildInfo describes the build of the plugin
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	ClientGo  string `json:"clientGo"`
+	// Kubernetes is the range of API server versions supported by the
+	// client-go version
+	Kubernetes string `json:"kubernetes"`
+}
+
+// VersionOptions provides information required to print the version
+type VersionOptions struct {
+	ns *NsOptions
+
+	output string
+}
+
+// NewVersionCmd provides a cobra command printing the build information
+func NewVersionCmd(ns *NsOptions) *cobra.Command {
+	opt := &VersionOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "version",
+		Short:        "Print the version and build information",
+		Example:      versionExample,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.Run(); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&opt.output, "output", "o", "", "output format, json prints the build information as JSON")
+
+	return cmd
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *VersionOptions) Validate() error {
+	if o.output != "" && o.output != outputJSON {
+		return fmt.Errorf("unsupported output format \"%s\", use json", o.output)
+	}
+	return nil
+}
+
+// Run prints the build information
+func (o *VersionOptions) Run() error {
+	info := buildInfo()
+	if o.output == outputJSON {
+		enc := json.NewEncoder(o.ns.Out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	w := tabwriter.NewWriter(o.ns.Out, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(w, "version:\t%s\n", info.Version)
+	fmt.Fprintf(w, "commit:\t%s\n", info.Commit)
+	fmt.Fprintf(w, "build date:\t%s\n", info.Date)
+	fmt.Fprintf(w, "go:\t%s %s\n", info.GoVersion, info.Platform)
+	fmt.Fprintf(w, "client-go:\t%s\n", info.ClientGo)
+	fmt.Fprintf(w, "kubernetes:\t%s\n", info.Kubernetes)
+	return w.Flush()
+}
+
+// buildInfo returns the build information set by the linker and the
+// versions of the go toolchain and client-go
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:    version,
+		Commit:     commit,
+		Date:       date,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		ClientGo:   "unknown",
+		Kubernetes: "unknown",
+	}
+	if b, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range b.Deps {
+			if dep.Path != clientGoModule {
+				continue
+			}
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			info.ClientGo = dep.Version
+			info.Kubernetes = kubernetesRange(dep.Version)
+		}
+	}
+	return info
+}
+
+// kubernetesRange returns the Kubernetes versions supported by a client-go
+// version v0.x.y, it is compatible with the API servers one minor version
+// older and newer than Kubernetes 1.x
+func kubernetesRange(clientGo string) string {
+	parts := strings.Split(strings.TrimPrefix(clientGo, "v"), ".")
+	if len(parts) < 2 || parts[0] != "0" {
+		return "unknown"
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 1 {
+		return "unknown"
+	}
+	return fmt.Sprintf("1.%d - 1.%d", minor-1, minor+1)
+}
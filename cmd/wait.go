@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	waitExample = `
+	# wait until the namespace foo exists and is active
+	kubectl ns wait foo
+
+	# wait up to 5 minutes until the namespace foo and its default service account exist
+	kubectl ns wait foo --service-account --timeout 5m`
+)
+
+// WaitOptions provides information required to wait for a namespace to
+// become ready
+type WaitOptions struct {
+	ns   *NsOptions
+	name string
+
+	timeout        time.Duration
+	serviceAccount bool
+}
+
+// NewWaitCmd provides a cobra command waiting until a namespace is ready
+func NewWaitCmd(ns *NsOptions) *cobra.Command {
+	opt := &WaitOptions{
+		ns: ns,
+	}
+
+	cmd := &cobra.Command{
+		Use:          "wait namespace",
+		Short:        "Wait until a namespace exists and is active",
+		Example:      waitExample,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opt.Complete(c, args); err != nil {
+				return err
+			}
+
+			if err := opt.Validate(); err != nil {
+				return err
+			}
+
+			if err := opt.ns.withLogin(opt.Run); err != nil {
+				return err
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&opt.timeout, "timeout", createTimeout, "maximum time to wait for the namespace")
+	cmd.Flags().BoolVar(&opt.serviceAccount, "service-account", false, "also wait for the default service account and its token secrets")
+
+	return cmd
+}
+
+// Complete sets all information required for waiting for the namespace
+func (o *WaitOptions) Complete(cmd *cobra.Command, args []string) error {
+	o.name = args[0]
+
+	if err := o.ns.loadConfig(); err != nil {
+		return err
+	}
+	if target, ok := o.ns.config.Aliases[o.name]; ok {
+		o.name = target
+	}
+
+	return nil
+}
+
+// Validate ensures that all required arguments and flag values are provided
+func (o *WaitOptions) Validate() error {
+	if o.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	return o.ns.checkContext()
+}
+
+// Run polls the namespace until it is ready or the timeout expired
+func (o *WaitOptions) Run() error {
+	if err := o.ns.waitReady(o.name, o.timeout, o.serviceAccount); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.ns.Out, "namespace \"%s\" is ready\n", o.name)
+
+	return nil
+}
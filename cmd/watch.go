@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// watchNamespaces prints the current listing and afterwards every change of
+// a namespace matching the user specified namespace until the watch fails
+func (o *NsOptions) watchNamespaces() error {
+	selected := []v1.Namespace{}
+	for _, ns := range o.namespaces.Items {
+		if o.matches(ns.GetName()) {
+			selected = append(selected, ns)
+		}
+	}
+	if err := o.printNamespaces(selected); err != nil {
+		return err
+	}
+
+	clientset, err := o.client()
+	if err != nil {
+		return err
+	}
+
+	resourceVersion := o.namespaces.GetResourceVersion()
+	for {
+		w, err := clientset.CoreV1().Namespaces().Watch(o.ctx, metav1.ListOptions{
+			LabelSelector:   o.labelSelector,
+			FieldSelector:   o.fieldSelector,
+			ResourceVersion: resourceVersion,
+		})
+		if err == nil {
+			resourceVersion, err = o.printEvents(w, resourceVersion)
+		} else {
+			err = fmt.Errorf("failed to watch namespaces: %w", err)
+		}
+		// the watch ends with the context on interrupt
+		if o.ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if resourceVersion != "" {
+			continue
+		}
+
+		// a watch without resource version would report every namespace
+		// as added, so the watch continues from the resource version of a
+		// new list
+		var namespaces *v1.NamespaceList
+		err = o.withRetry(func() (err error) {
+			namespaces, err = clientset.CoreV1().Namespaces().List(o.ctx, metav1.ListOptions{
+				LabelSelector: o.labelSelector,
+				FieldSelector: o.fieldSelector,
+				Limit:         1,
+			})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get namespaces: %w", err)
+		}
+		resourceVersion = namespaces.GetResourceVersion()
+	}
+}
+
+// printEvents prints all events of w until the result channel is closed and
+// returns the resource version to resume from, it is empty if the resource
+// version expired
+func (o *NsOptions) printEvents(w watch.Interface, resourceVersion string) (string, error) {
+	defer w.Stop()
+
+	for event := range w.ResultChan() {
+		if event.Type == watch.Error {
+			status := apierrors.FromObject(event.Object)
+			if apierrors.IsResourceExpired(status) || apierrors.IsGone(status) {
+				// start watching from the most recent state
+				return "", nil
+			}
+			return "", fmt.Errorf("failed to watch namespaces: %w", status)
+		}
+
+		ns, ok := event.Object.(*v1.Namespace)
+		if !ok {
+			continue
+		}
+		resourceVersion = ns.GetResourceVersion()
+
+		if !o.matches(ns.GetName()) || o.isHidden(ns.GetName()) {
+			continue
+		}
+		fmt.Fprintf(o.Out, "%-8s %s %s\n", event.Type, ns.GetName(), ns.Status.Phase)
+	}
+
+	return resourceVersion, nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/config"
+)
+
+// defaultWebhookTimeout is the timeout of webhook requests if nothing else
+// is configured
+const defaultWebhookTimeout = 5 * time.Second
+
+// webhookEvent is the JSON body sent to the webhook
+type webhookEvent struct {
+	User          string    `json:"user"`
+	KubeUser      string    `json:"kubeUser,omitempty"`
+	Server        string    `json:"server,omitempty"`
+	FromContext   string    `json:"fromContext"`
+	ToContext     string    `json:"toContext"`
+	FromNamespace string    `json:"fromNamespace"`
+	ToNamespace   string    `json:"toNamespace"`
+	Time          time.Time `json:"time"`
+}
+
+// notifyWebhook posts the switch to the configured webhook, failures are
+// reported as warnings to w
+func (o *NsOptions) notifyWebhook(e switchEvent, w io.Writer) {
+	hook := o.config.Webhook
+	if hook == nil || (len(hook.Namespaces) > 0 && !config.MatchAny(hook.Namespaces, e.ToNamespace)) {
+		return
+	}
+
+	if err := o.postWebhook(hook, o.webhookEvent(e)); err != nil {
+		fmt.Fprintf(w, "warning: failed to notify the webhook: %v\n", err)
+	}
+}
+
+// webhookEvent adds the local user and the user and server of the target
+// context to the switch
+func (o *NsOptions) webhookEvent(e switchEvent) webhookEvent {
+	event := webhookEvent{
+		FromContext:   e.FromContext,
+		ToContext:     e.ToContext,
+		FromNamespace: e.FromNamespace,
+		ToNamespace:   e.ToNamespace,
+		Time:          time.Now(),
+		User:          localUser(),
+	}
+	if ctx, ok := o.rawConfig.Contexts[e.ToContext]; ok {
+		event.KubeUser = ctx.AuthInfo
+		if cluster, ok := o.rawConfig.Clusters[ctx.Cluster]; ok {
+			event.Server = cluster.Server
+		}
+	}
+	return event
+}
+
+func (o *NsOptions) postWebhook(hook *config.Webhook, event webhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultWebhookTimeout
+	if hook.Timeout != nil {
+		timeout = hook.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(o.ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
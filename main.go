@@ -1,19 +1,57 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/postfinance/kubectl-ns/cmd"
 	"github.com/spf13/pflag"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+// shutdownTimeout is the time running requests get to finish after an
+// interrupt
+const shutdownTimeout = time.Second
+
 func main() {
 	flags := pflag.NewFlagSet("kubectl-ns", pflag.ExitOnError)
 	pflag.CommandLine = flags
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// signals during a child process are handled by the child
+		for range signals {
+			if !cmd.ChildRunning() {
+				break
+			}
+		}
+		cancel()
+		select {
+		case <-signals:
+		case <-time.After(shutdownTimeout):
+		}
+		os.Exit(130)
+	}()
+
 	root := cmd.NewNsCmd(genericclioptions.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr})
-	if err := root.Execute(); err != nil {
+	if err := root.ExecuteContext(ctx); err != nil {
+		var exitErr *cmd.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if ctx.Err() != nil {
+			os.Exit(130)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", cmd.ExplainError(err))
 		os.Exit(1)
 	}
 }
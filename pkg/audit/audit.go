@@ -0,0 +1,96 @@
+// Package audit keeps an append-only log of namespace switches in a local
+// JSON lines file.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/state"
+)
+
+// Entry describes a single namespace switch
+type Entry struct {
+	Time          time.Time `json:"time"`
+	User          string    `json:"user"`
+	Context       string    `json:"context"`
+	Cluster       string    `json:"cluster,omitempty"`
+	Server        string    `json:"server,omitempty"`
+	FromContext   string    `json:"fromContext,omitempty"`
+	FromNamespace string    `json:"fromNamespace"`
+	ToNamespace   string    `json:"toNamespace"`
+}
+
+// Log is an audit log file, entries are only appended and never pruned
+type Log struct {
+	Path string
+}
+
+// NewDefaultLog returns the log configured by KUBECTL_NS_AUDIT_LOG, falling
+// back to audit.jsonl in the state directory
+func NewDefaultLog() (*Log, error) {
+	l := &Log{
+		Path: os.Getenv("KUBECTL_NS_AUDIT_LOG"),
+	}
+
+	if l.Path == "" {
+		dir, err := state.Dir()
+		if err != nil {
+			return nil, err
+		}
+		l.Path = filepath.Join(dir, "audit.jsonl")
+	}
+
+	return l, nil
+}
+
+// List returns all entries, oldest first
+func (l *Log) List() ([]Entry, error) {
+	data, err := ioutil.ReadFile(l.Path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		e := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Append adds e to the end of the log without rewriting existing entries
+func (l *Log) Append(e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
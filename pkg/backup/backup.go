@@ -0,0 +1,179 @@
+// Package backup keeps copies of the kubeconfig files taken before they are
+// modified, so a modification can be rolled back.
+package backup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	"github.com/postfinance/kubectl-ns/pkg/state"
+)
+
+// DefaultKeep is the number of backups kept if nothing else is configured
+const DefaultKeep = 10
+
+const (
+	manifestName = "backup.json"
+	timeFormat   = "20060102-150405.000000000"
+)
+
+// Backup is a copy of the kubeconfig files at one point in time
+type Backup struct {
+	Dir   string    `json:"-"`
+	Time  time.Time `json:"time"`
+	Files []File    `json:"files"`
+}
+
+// File is a kubeconfig file stored in a backup under Name
+type File struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+// Store is a directory with one subdirectory per backup, at most Keep
+// backups are kept
+type Store struct {
+	Dir  string
+	Keep int
+}
+
+// NewDefaultStore returns the store in the backups directory inside the
+// state directory which keeps the given number of backups
+func NewDefaultStore(keep int) (*Store, error) {
+	dir, err := state.Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Dir: filepath.Join(dir, "backups"), Keep: keep}, nil
+}
+
+// Create copies the existing files of paths into a new backup and removes
+// the oldest backups beyond Keep
+func (s *Store) Create(paths []string) (*Backup, error) {
+	now := time.Now()
+	b := &Backup{
+		Dir:  filepath.Join(s.Dir, now.UTC().Format(timeFormat)),
+		Time: now,
+	}
+	if err := os.MkdirAll(b.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	for i, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		f := File{Path: path, Name: fmt.Sprintf("%d-%s", i, filepath.Base(path))}
+		if err := ioutil.WriteFile(filepath.Join(b.Dir, f.Name), data, 0600); err != nil {
+			return nil, err
+		}
+		b.Files = append(b.Files, f)
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(b.Dir, manifestName), data, 0600); err != nil {
+		return nil, err
+	}
+	return b, s.prune()
+}
+
+// List returns all backups, oldest first
+func (s *Store) List() ([]Backup, error) {
+	dirs, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []Backup{}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(s.Dir, d.Name(), manifestName))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		b := Backup{Dir: filepath.Join(s.Dir, d.Name())}
+		if err := json.Unmarshal(data, &b); err != nil {
+			return nil, fmt.Errorf("invalid backup %s: %w", d.Name(), err)
+		}
+		backups = append(backups, b)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].Time.Before(backups[j].Time)
+	})
+	return backups, nil
+}
+
+func (s *Store) prune() error {
+	if s.Keep <= 0 {
+		return nil
+	}
+	backups, err := s.List()
+	if err != nil {
+		return err
+	}
+	for len(backups) > s.Keep {
+		if err := os.RemoveAll(backups[0].Dir); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// Content is a file of a backup read into memory
+type Content struct {
+	Path string
+	Data []byte
+}
+
+// Read returns the files of the backup, they can still be restored after
+// the backup was pruned
+func (b *Backup) Read() ([]Content, error) {
+	contents := make([]Content, 0, len(b.Files))
+	for _, f := range b.Files {
+		data, err := ioutil.ReadFile(filepath.Join(b.Dir, f.Name))
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, Content{Path: f.Path, Data: data})
+	}
+	return contents, nil
+}
+
+// Restore writes the contents back to their original location
+func Restore(contents []Content) error {
+	for _, c := range contents {
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
+			return err
+		}
+		if err := safefile.WriteFile(c.Path, c.Data, 0600); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", c.Path, err)
+		}
+	}
+	return nil
+}
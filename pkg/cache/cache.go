@@ -0,0 +1,117 @@
+// Package cache stores namespace lists per cluster and prompt information on disk.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// Cache stores namespace lists below Dir, entries older than TTL are
+// considered stale
+type Cache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// Entry is a cached namespace list
+type Entry struct {
+	Time       time.Time      `json:"time"`
+	Namespaces []v1.Namespace `json:"namespaces"`
+}
+
+// Dir returns the cache directory. It honours KUBECTL_NS_CACHE_DIR and
+// falls back to kubectl-ns inside the users cache directory.
+func Dir() (string, error) {
+	if dir := os.Getenv("KUBECTL_NS_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "kubectl-ns"), nil
+}
+
+// New returns a cache in the default directory
+func New(ttl time.Duration) (*Cache, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{
+		Dir: dir,
+		TTL: ttl,
+	}, nil
+}
+
+// Key returns the cache key for a cluster accessed by a user
+func Key(server, user string) string {
+	sum := sha256.Sum256([]byte(server + "\x00" + user))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// Get returns the namespaces cached for key, ok is false if no entry
+// exists or if the entry is stale
+func (c *Cache) Get(key string) (namespaces []v1.Namespace, ok bool, err error) {
+	e, err := c.Load(key)
+	if err != nil || e == nil || c.Stale(e) {
+		return nil, false, err
+	}
+	return e.Namespaces, true, nil
+}
+
+// Load returns the entry for key regardless of its age, or nil if no entry
+// exists
+func (c *Cache) Load(key string) (*Entry, error) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Entry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Stale reports whether e is older than the TTL of the cache
+func (c *Cache) Stale(e *Entry) bool {
+	return time.Since(e.Time) > c.TTL
+}
+
+// Set stores namespaces for key
+func (c *Cache) Set(key string, namespaces []v1.Namespace) error {
+	stripped := make([]v1.Namespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		ns.SetManagedFields(nil)
+		stripped = append(stripped, ns)
+	}
+
+	data, err := json.Marshal(Entry{
+		Time:       time.Now(),
+		Namespaces: stripped,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path(key), data, 0600)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
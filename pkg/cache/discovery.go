@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Discovery is the result of the discovery requests for a cluster, it is
+// stored next to the namespace list of the cluster
+type Discovery struct {
+	Time      time.Time `json:"time"`
+	OpenShift bool      `json:"openshift"`
+}
+
+// LoadDiscovery returns the discovery result cached for key, or nil if no
+// entry exists or if the entry is stale
+func (c *Cache) LoadDiscovery(key string) (*Discovery, error) {
+	data, err := ioutil.ReadFile(c.discoveryPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Discovery{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, err
+	}
+	if time.Since(d.Time) > c.TTL {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// SetDiscovery stores the discovery result for key
+func (c *Cache) SetDiscovery(key string, d Discovery) error {
+	d.Time = time.Now()
+	data, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.discoveryPath(key), data, 0600)
+}
+
+func (c *Cache) discoveryPath(key string) string {
+	return filepath.Join(c.Dir, key+"-discovery.json")
+}
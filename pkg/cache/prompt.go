@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const promptFile = "prompt.json"
+
+// Prompt is the current context and namespace read from the kubeconfig
+// files. It stays valid as long as none of the files changed.
+type Prompt struct {
+	Files     []File `json:"files"`
+	Context   string `json:"context"`
+	Namespace string `json:"namespace"`
+}
+
+// File identifies the version of a kubeconfig file, missing files have a
+// zero modification time
+type File struct {
+	Path    string    `json:"path"`
+	ModTime time.Time `json:"modTime"`
+	Size    int64     `json:"size"`
+}
+
+// Stat returns the current version of the files in paths
+func Stat(paths []string) []File {
+	files := make([]File, 0, len(paths))
+	for _, path := range paths {
+		f := File{Path: path}
+		if info, err := os.Stat(path); err == nil {
+			f.ModTime = info.ModTime()
+			f.Size = info.Size()
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// Valid reports whether p was created from exactly the given files
+func (p *Prompt) Valid(files []File) bool {
+	if len(p.Files) != len(files) {
+		return false
+	}
+	for i, f := range files {
+		if p.Files[i].Path != f.Path || p.Files[i].Size != f.Size || !p.Files[i].ModTime.Equal(f.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadPrompt returns the cached prompt, or nil if none exists
+func (c *Cache) LoadPrompt() (*Prompt, error) {
+	data, err := ioutil.ReadFile(filepath.Join(c.Dir, promptFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Prompt{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPrompt stores p
+func (c *Cache) SetPrompt(p *Prompt) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(c.Dir, promptFile), data, 0600)
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// VClusters are the vclusters per host namespace of a cluster, they are
+// stored next to the namespace list of the cluster
+type VClusters struct {
+	Time  time.Time           `json:"time"`
+	Hosts map[string][]string `json:"hosts"`
+}
+
+// LoadVClusters returns the vclusters cached for key, or nil if no entry
+// exists or if the entry is stale
+func (c *Cache) LoadVClusters(key string) (*VClusters, error) {
+	data, err := ioutil.ReadFile(c.vclustersPath(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := &VClusters{}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	if time.Since(v.Time) > c.TTL {
+		return nil, nil
+	}
+	return v, nil
+}
+
+// SetVClusters stores the vclusters per host namespace for key
+func (c *Cache) SetVClusters(key string, hosts map[string][]string) error {
+	data, err := json.Marshal(VClusters{
+		Time:  time.Now(),
+		Hosts: hosts,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.vclustersPath(key), data, 0600)
+}
+
+func (c *Cache) vclustersPath(key string) string {
+	return filepath.Join(c.Dir, key+"-vclusters.json")
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxNamespaceLength is the maximum length of a namespace name
+const maxNamespaceLength = 63
+
+var invalidNamespaceChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// BranchRule derives a namespace from the git branches matching Pattern
+type BranchRule struct {
+	// Pattern is a regular expression matching the whole branch name,
+	// e.g. feature/(.*)
+	Pattern string `json:"pattern"`
+	// Namespace is the template of the namespace, $1 or ${name} refer to
+	// the groups of the pattern, e.g. preview-$1
+	Namespace string `json:"namespace"`
+	// Create creates the namespace if it does not exist yet
+	Create bool `json:"create,omitempty"`
+}
+
+// ValidateBranches ensures that all branch rules have a valid pattern and a
+// namespace template
+func ValidateBranches(rules []BranchRule) error {
+	for i, r := range rules {
+		if _, err := regexp.Compile("^(?:" + r.Pattern + ")$"); err != nil {
+			return fmt.Errorf("branches[%d]: invalid pattern: %w", i, err)
+		}
+		if r.Namespace == "" {
+			return fmt.Errorf("branches[%d]: namespace missing", i)
+		}
+	}
+	return nil
+}
+
+// BranchNamespace returns the namespace of the first rule matching branch.
+// The expanded template is turned into a valid namespace name, characters
+// which are not allowed are replaced by dashes.
+func (c *Config) BranchNamespace(branch string) (namespace string, rule BranchRule, ok bool) {
+	for _, r := range c.Branches {
+		pattern, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			continue
+		}
+		match := pattern.FindStringSubmatchIndex(branch)
+		if match == nil {
+			continue
+		}
+		expanded := pattern.ExpandString(nil, r.Namespace, branch, match)
+		return sanitizeNamespace(string(expanded)), r, true
+	}
+	return "", BranchRule{}, false
+}
+
+// sanitizeNamespace lowercases name, replaces invalid characters by dashes
+// and shortens it to the maximum length of namespace names
+func sanitizeNamespace(name string) string {
+	name = invalidNamespaceChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > maxNamespaceLength {
+		name = name[:maxNamespaceLength]
+	}
+	return strings.Trim(name, "-")
+}
@@ -0,0 +1,254 @@
+// Package config loads the kubectl-ns configuration file.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Sort orders of the namespace list
+const (
+	SortAscending  = "asc"
+	SortDescending = "desc"
+)
+
+// Sort keys of the namespace list
+const (
+	SortByName     = "name"
+	SortByCreation = "creation"
+	SortByPhase    = "phase"
+	SortByLastUsed = "last-used"
+)
+
+// DefaultSystemNamespaces are the patterns of system namespaces used if
+// nothing is configured
+var DefaultSystemNamespaces = []string{"kube-*"}
+
+// SortKeys are all supported sort keys
+var SortKeys = []string{SortByName, SortByCreation, SortByPhase, SortByLastUsed}
+
+// Config is the content of the configuration file, every setting can be
+// overridden by the corresponding flag
+type Config struct {
+	// Color enables or disables colored output, by default colors are
+	// used if the output is a terminal
+	Color *bool `json:"color,omitempty"`
+	// SortOrder of the namespace list, either asc (default) or desc
+	SortOrder string `json:"sortOrder,omitempty"`
+	// SortBy is the sort key of the namespace list, one of SortKeys
+	SortBy string `json:"sortBy,omitempty"`
+	// Include lists namespaces which are shown in listings and the
+	// interactive picker, all others are hidden. Shell patterns like
+	// team-a-* are supported, by default all namespaces are shown.
+	Include []string `json:"include,omitempty"`
+	// Exclude lists namespaces which are hidden in listings and the
+	// interactive picker, shell patterns like *-canary are supported
+	Exclude []string `json:"exclude,omitempty"`
+	// SystemNamespaces lists the patterns of system namespaces, by default
+	// DefaultSystemNamespaces
+	SystemNamespaces []string `json:"systemNamespaces,omitempty"`
+	// HideSystem hides system namespaces in listings without argument and
+	// the interactive picker, they are hidden by default
+	HideSystem *bool `json:"hideSystem,omitempty"`
+	// Protected lists namespaces which require a confirmation before
+	// switching to them, shell patterns like prod-* are supported
+	Protected []string `json:"protected,omitempty"`
+	// Aliases maps short names to namespaces, e.g. prod to
+	// payments-production-eu1
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Defaults configures the namespace used by --default
+	Defaults Defaults `json:"defaults,omitempty"`
+	// Interactive enables or disables the interactive picker, it is
+	// enabled by default if input and output are a terminal
+	Interactive *bool `json:"interactive,omitempty"`
+	// Fuzzy enables fuzzy matching of the namespace argument
+	Fuzzy *bool `json:"fuzzy,omitempty"`
+	// Retries is the number of retries of namespace requests failing with
+	// transient errors
+	Retries *int `json:"retries,omitempty"`
+	// RetryBackoff is the delay before the first retry, e.g. 200ms
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+	// Theme configures the highlighting of the namespace list
+	Theme Theme `json:"theme,omitempty"`
+	// Branches derive namespaces from git branches for --from-branch, the
+	// first matching rule is used
+	Branches []BranchRule `json:"branches,omitempty"`
+	// Hooks are commands run before and after namespace switches
+	Hooks Hooks `json:"hooks,omitempty"`
+	// Webhook receives an event after every namespace switch
+	Webhook *Webhook `json:"webhook,omitempty"`
+	// KubeconfigState stores the previous and recent namespaces and the
+	// favorites of a context in its extensions in the kubeconfig, so they
+	// travel with the file. The local state file is used as well.
+	KubeconfigState *bool `json:"kubeconfigState,omitempty"`
+	// Backups is the number of kubeconfig backups kept, 10 by default. A
+	// value of 0 disables the backups.
+	Backups *int `json:"backups,omitempty"`
+	// OIDCDeviceLogin logs an oidc user in again by the device
+	// authorization grant if the refresh token expired, instead of failing
+	OIDCDeviceLogin *bool `json:"oidcDeviceLogin,omitempty"`
+	// Templates bootstrap namespaces created with --template, mapped by
+	// their name
+	Templates map[string]Template `json:"templates,omitempty"`
+}
+
+// Webhook is a URL receiving a JSON event per namespace switch by POST
+type Webhook struct {
+	URL string `json:"url"`
+	// Namespaces restricts the events to switches to namespaces matching
+	// these patterns, by default every switch is sent
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Headers are added to the request, e.g. Authorization
+	Headers map[string]string `json:"headers,omitempty"`
+	// Timeout of the request, 5s by default
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// Hooks are shell commands run around namespace switches, they receive the
+// old and new context and namespace in the environment variables
+// KUBECTL_NS_FROM_CONTEXT, KUBECTL_NS_TO_CONTEXT, KUBECTL_NS_FROM_NAMESPACE
+// and KUBECTL_NS_TO_NAMESPACE
+type Hooks struct {
+	// PreSwitch commands run before the switch, a non-zero exit code
+	// vetoes the switch
+	PreSwitch []string `json:"preSwitch,omitempty"`
+	// PostSwitch commands run after the switch
+	PostSwitch []string `json:"postSwitch,omitempty"`
+}
+
+// Defaults maps contexts and clusters to their default namespace, a
+// context entry takes precedence over the entry of its cluster
+type Defaults struct {
+	Contexts map[string]string `json:"contexts,omitempty"`
+	Clusters map[string]string `json:"clusters,omitempty"`
+}
+
+// Path returns the location of the configuration file. It honours
+// KUBECTL_NS_CONFIG and XDG_CONFIG_HOME and falls back to
+// ~/.config/kubectl-ns/config.yaml.
+func Path() (string, error) {
+	if p := os.Getenv("KUBECTL_NS_CONFIG"); p != "" {
+		return p, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "kubectl-ns", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "kubectl-ns", "config.yaml"), nil
+}
+
+// Load reads the configuration from p, a missing file results in the
+// default configuration
+func Load(p string) (*Config, error) {
+	c := &Config{}
+
+	data, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, err
+	}
+	if err := ValidateSortOrder(c.SortOrder); err != nil {
+		return nil, err
+	}
+	if err := ValidateSortBy(c.SortBy); err != nil {
+		return nil, err
+	}
+	if err := c.Theme.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateBranches(c.Branches); err != nil {
+		return nil, err
+	}
+	if c.Webhook != nil && c.Webhook.URL == "" {
+		return nil, fmt.Errorf("webhook: url missing")
+	}
+	if err := ValidateTemplates(c.Templates); err != nil {
+		return nil, err
+	}
+	if c.Backups != nil && *c.Backups < 0 {
+		return nil, fmt.Errorf("backups must not be negative")
+	}
+	return c, nil
+}
+
+// ValidateSortOrder ensures order is empty, asc or desc
+func ValidateSortOrder(order string) error {
+	switch order {
+	case "", SortAscending, SortDescending:
+		return nil
+	}
+	return fmt.Errorf("invalid sort order \"%s\", must be %s or %s", order, SortAscending, SortDescending)
+}
+
+// ValidateSortBy ensures key is empty or one of SortKeys
+func ValidateSortBy(key string) error {
+	if key == "" {
+		return nil
+	}
+	for _, k := range SortKeys {
+		if key == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid sort key \"%s\", must be one of %s", key, strings.Join(SortKeys, ", "))
+}
+
+// LoadDefault reads the configuration from the default location
+func LoadDefault() (*Config, error) {
+	p, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return Load(p)
+}
+
+// DefaultNamespace returns the default namespace of a context using the
+// given cluster, the namespace default is used if nothing is configured
+func (c *Config) DefaultNamespace(context, cluster string) string {
+	if ns, ok := c.Defaults.Contexts[context]; ok {
+		return ns
+	}
+	if ns, ok := c.Defaults.Clusters[cluster]; ok {
+		return ns
+	}
+	return "default"
+}
+
+// IsSystem reports whether namespace is a system namespace
+func (c *Config) IsSystem(namespace string) bool {
+	if c.SystemNamespaces == nil {
+		return MatchAny(DefaultSystemNamespaces, namespace)
+	}
+	return MatchAny(c.SystemNamespaces, namespace)
+}
+
+// IsProtected reports whether switching to namespace requires a confirmation
+func (c *Config) IsProtected(namespace string) bool {
+	return MatchAny(c.Protected, namespace)
+}
+
+// MatchAny reports whether name matches one of the shell patterns
+func MatchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
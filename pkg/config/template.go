@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Template bootstraps namespaces created by kubectl ns create --template
+// with labels, annotations and objects
+type Template struct {
+	// Labels of the namespace, labels passed by --labels take precedence
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations of the namespace, annotations passed by --annotations
+	// take precedence
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Manifests are the namespaced objects created in the namespace in
+	// this order, e.g. network policies, resource quotas and role
+	// bindings. Their namespace is set to the created namespace.
+	Manifests []map[string]interface{} `json:"manifests,omitempty"`
+}
+
+// ValidateTemplates ensures that every manifest of the templates has an
+// apiVersion, a kind and a name
+func ValidateTemplates(templates map[string]Template) error {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for i, m := range templates[name].Manifests {
+			metadata, _ := m["metadata"].(map[string]interface{})
+			fields := []string{"apiVersion", "kind", "metadata.name"}
+			for j, value := range []interface{}{m["apiVersion"], m["kind"], metadata["name"]} {
+				if s, ok := value.(string); !ok || s == "" {
+					return fmt.Errorf("templates.%s.manifests[%d]: %s missing", name, i, fields[j])
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Colors supported by styles, none disables the color
+var Colors = []string{"none", "black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// Theme configures how namespaces are highlighted in the namespace list
+type Theme struct {
+	// Current is the style of the current namespace, by default it is
+	// printed in red
+	Current Style `json:"current,omitempty"`
+	// Labels styles namespaces matching a label selector, the first
+	// matching entry is used
+	Labels []LabelStyle `json:"labels,omitempty"`
+}
+
+// Style of a namespace in the namespace list
+type Style struct {
+	// Color of the name, one of Colors
+	Color string `json:"color,omitempty"`
+	// Bold prints the name in bold
+	Bold bool `json:"bold,omitempty"`
+	// Prefix is printed in front of the name, e.g. "* "
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// LabelStyle is the style of namespaces matching Selector, e.g. env=prod
+type LabelStyle struct {
+	Selector string `json:"selector"`
+	Style    `json:",inline"`
+}
+
+// Validate ensures that all colors and label selectors of the theme are
+// valid
+func (t Theme) Validate() error {
+	if err := t.Current.validate(); err != nil {
+		return fmt.Errorf("theme.current: %w", err)
+	}
+	for i, l := range t.Labels {
+		if _, err := labels.Parse(l.Selector); err != nil {
+			return fmt.Errorf("theme.labels[%d]: invalid selector: %w", i, err)
+		}
+		if err := l.validate(); err != nil {
+			return fmt.Errorf("theme.labels[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// LabelStyle returns the style of the first label selector matching set
+func (t Theme) LabelStyle(set map[string]string) (Style, bool) {
+	for _, l := range t.Labels {
+		selector, err := labels.Parse(l.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(set)) {
+			return l.Style, true
+		}
+	}
+	return Style{}, false
+}
+
+func (s Style) validate() error {
+	if s.Color == "" {
+		return nil
+	}
+	for _, c := range Colors {
+		if s.Color == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid color \"%s\"", s.Color)
+}
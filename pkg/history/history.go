@@ -0,0 +1,153 @@
+// Package history records namespace switches in a local JSON lines file.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/postfinance/kubectl-ns/pkg/safefile"
+	"github.com/postfinance/kubectl-ns/pkg/state"
+)
+
+// DefaultMaxEntries is the number of entries kept if nothing else is configured
+const DefaultMaxEntries = 1000
+
+// Entry describes a single namespace switch. PreviousContext is set if the
+// switch changed the current context as well, Undone once it was reverted.
+type Entry struct {
+	Time            time.Time `json:"time"`
+	Context         string    `json:"context"`
+	From            string    `json:"from"`
+	To              string    `json:"to"`
+	PreviousContext string    `json:"previousContext,omitempty"`
+	Undone          bool      `json:"undone,omitempty"`
+}
+
+// Store is a history file which keeps at most MaxEntries entries, a value
+// of zero or below disables pruning
+type Store struct {
+	Path       string
+	MaxEntries int
+}
+
+// NewDefaultStore returns the store configured by KUBECTL_NS_HISTORY and
+// KUBECTL_NS_HISTORY_SIZE, falling back to history.jsonl in the state
+// directory and DefaultMaxEntries
+func NewDefaultStore() (*Store, error) {
+	s := &Store{
+		Path:       os.Getenv("KUBECTL_NS_HISTORY"),
+		MaxEntries: DefaultMaxEntries,
+	}
+
+	if s.Path == "" {
+		dir, err := state.Dir()
+		if err != nil {
+			return nil, err
+		}
+		s.Path = filepath.Join(dir, "history.jsonl")
+	}
+
+	if size := os.Getenv("KUBECTL_NS_HISTORY_SIZE"); size != "" {
+		max, err := strconv.Atoi(size)
+		if err != nil {
+			return nil, err
+		}
+		s.MaxEntries = max
+	}
+
+	return s, nil
+}
+
+// List returns all entries, oldest first
+func (s *Store) List() ([]Entry, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return []Entry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		e := Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// Append adds e to the history and prunes the oldest entries if the
+// history grows beyond MaxEntries
+func (s *Store) Append(e Entry) error {
+	return s.update(func(entries []Entry) ([]Entry, error) {
+		entries = append(entries, e)
+		if s.MaxEntries > 0 && len(entries) > s.MaxEntries {
+			entries = entries[len(entries)-s.MaxEntries:]
+		}
+		return entries, nil
+	})
+}
+
+// Replace overwrites the most recent entry equal to old with e. Entries are
+// matched by content as other processes may have appended or pruned entries
+// since old was listed.
+func (s *Store) Replace(old, e Entry) error {
+	return s.update(func(entries []Entry) ([]Entry, error) {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].equal(old) {
+				entries[i] = e
+				return entries, nil
+			}
+		}
+		return nil, fmt.Errorf("history entry of the switch to \"%s\" does not exist anymore", old.To)
+	})
+}
+
+func (e Entry) equal(o Entry) bool {
+	return e.Time.Equal(o.Time) && e.Context == o.Context && e.From == o.From && e.To == o.To &&
+		e.PreviousContext == o.PreviousContext && e.Undone == o.Undone
+}
+
+// update applies fn to the entries while holding the lock of the history
+// file, concurrent switches would otherwise overwrite each other's entries
+func (s *Store) update(fn func([]Entry) ([]Entry, error)) error {
+	unlock, err := state.Lock(s.Path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := s.List()
+	if err != nil {
+		return err
+	}
+	if entries, err = fn(entries); err != nil {
+		return err
+	}
+	return s.write(entries)
+}
+
+func (s *Store) write(entries []Entry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return safefile.WriteFile(s.Path, buf.Bytes(), 0600)
+}
@@ -0,0 +1,207 @@
+// Package oidc implements the OAuth 2.0 device authorization grant (RFC
+// 8628) against an OpenID Connect provider, it is used to log in again when
+// the refresh token of a kubeconfig user expired.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Provider is an OpenID Connect provider and the client registered with it
+type Provider struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// Scopes are requested in addition to openid and offline_access
+	Scopes []string
+	Client *http.Client
+}
+
+// DeviceAuthorization is the response of the device authorization endpoint,
+// the user has to enter UserCode at VerificationURI
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	// VerificationURL is used by some providers instead of VerificationURI
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+
+	tokenEndpoint string
+}
+
+// URI returns the verification URI, including the user code if the
+// provider supports it
+func (a *DeviceAuthorization) URI() string {
+	switch {
+	case a.VerificationURIComplete != "":
+		return a.VerificationURIComplete
+	case a.VerificationURI != "":
+		return a.VerificationURI
+	}
+	return a.VerificationURL
+}
+
+// Token contains the tokens issued after a successful login
+type Token struct {
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type discovery struct {
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+type tokenError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Authorize starts a device login, the returned authorization has to be
+// shown to the user before polling for the token
+func (p *Provider) Authorize(ctx context.Context) (*DeviceAuthorization, error) {
+	d := discovery{}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.IssuerURL, "/")+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("failed to discover the provider: %w", err)
+	}
+	if d.DeviceAuthorizationEndpoint == "" || d.TokenEndpoint == "" {
+		return nil, fmt.Errorf("the provider %s does not support the device authorization grant", p.IssuerURL)
+	}
+
+	form := p.form()
+	form.Set("scope", strings.Join(append([]string{"openid", "offline_access"}, p.Scopes...), " "))
+	a := &DeviceAuthorization{}
+	status, err := p.postForm(ctx, d.DeviceAuthorizationEndpoint, form, a)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("device authorization failed with status %d", status)
+	}
+	if a.DeviceCode == "" || a.URI() == "" {
+		return nil, fmt.Errorf("invalid device authorization response")
+	}
+	if a.Interval <= 0 {
+		a.Interval = 5
+	}
+	a.tokenEndpoint = d.TokenEndpoint
+	return a, nil
+}
+
+// Poll waits until the user completed the login of the authorization and
+// returns the issued tokens
+func (p *Provider) Poll(ctx context.Context, a *DeviceAuthorization) (*Token, error) {
+	interval := time.Duration(a.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(a.ExpiresIn) * time.Second)
+
+	form := p.form()
+	form.Set("grant_type", deviceCodeGrant)
+	form.Set("device_code", a.DeviceCode)
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if a.ExpiresIn > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("the login was not completed in time")
+		}
+
+		var raw json.RawMessage
+		status, err := p.postForm(ctx, a.tokenEndpoint, form, &raw)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusOK {
+			t := &Token{}
+			if err := json.Unmarshal(raw, t); err != nil {
+				return nil, err
+			}
+			if t.IDToken == "" {
+				return nil, fmt.Errorf("the token response contains no id_token")
+			}
+			return t, nil
+		}
+
+		e := tokenError{}
+		_ = json.Unmarshal(raw, &e)
+		switch e.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "":
+			return nil, fmt.Errorf("token request failed with status %d", status)
+		default:
+			return nil, fmt.Errorf("login failed: %s %s", e.Error, e.ErrorDescription)
+		}
+	}
+}
+
+func (p *Provider) form() url.Values {
+	form := url.Values{}
+	form.Set("client_id", p.ClientID)
+	if p.ClientSecret != "" {
+		form.Set("client_secret", p.ClientSecret)
+	}
+	return form
+}
+
+func (p *Provider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// postForm posts the form and decodes the JSON response into v, the status
+// code is returned as errors are reported in the body
+func (p *Provider) postForm(ctx context.Context, u string, form url.Values, v interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := p.client().Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("POST %s: invalid response with status %d", u, resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (p *Provider) client() *http.Client {
+	if p.Client != nil {
+		return p.Client
+	}
+	return http.DefaultClient
+}
@@ -0,0 +1,120 @@
+// Package project reads and writes project files pinning the namespace of a
+// directory tree.
+package project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FileName is the plain project file, it contains the namespace or
+	// context:namespace
+	FileName = ".kubens"
+	// YAMLFileName is the project file in YAML format
+	YAMLFileName = ".kubectl-ns.yaml"
+)
+
+// File is a project file pinning a namespace and optionally a context. Sum
+// is the SHA-256 of its content, a trusted file is only trusted as long as
+// it doesn't change.
+type File struct {
+	Path      string `json:"-"`
+	Sum       string `json:"-"`
+	Context   string `json:"context,omitempty"`
+	Namespace string `json:"namespace"`
+}
+
+// Find searches dir and its parents for a project file, a plain file takes
+// precedence over a YAML file in the same directory. Nil is returned if no
+// project file exists.
+func Find(dir string) (*File, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range []string{FileName, YAMLFileName} {
+			path := filepath.Join(dir, name)
+			f, err := Load(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			return f, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads the project file at path, the format is chosen by the file
+// name
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{Path: path, Sum: sum(data)}
+	if filepath.Base(path) == YAMLFileName {
+		if err := yaml.UnmarshalStrict(data, f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else {
+		f.Context, f.Namespace = parse(string(data))
+	}
+
+	if f.Namespace == "" {
+		return nil, fmt.Errorf("no namespace set in %s", path)
+	}
+	return f, nil
+}
+
+// parse returns the context and namespace of a plain project file, empty
+// lines and comments starting with # are ignored
+func parse(content string) (context, namespace string) {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.LastIndex(line, ":"); i >= 0 {
+			return line[:i], line[i+1:]
+		}
+		return "", line
+	}
+	return "", ""
+}
+
+// String returns the pinned namespace like it is passed as argument, either
+// namespace or context:namespace
+func (f *File) String() string {
+	if f.Context == "" {
+		return f.Namespace
+	}
+	return f.Context + ":" + f.Namespace
+}
+
+// Write creates the plain project file in dir
+func (f *File) Write(dir string) error {
+	data := []byte(f.String() + "\n")
+	f.Path, f.Sum = filepath.Join(dir, FileName), sum(data)
+	return ioutil.WriteFile(f.Path, data, 0644)
+}
+
+func sum(data []byte) string {
+	s := sha256.Sum256(data)
+	return hex.EncodeToString(s[:])
+}
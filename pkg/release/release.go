@@ -0,0 +1,230 @@
+// Package release finds and downloads the binaries published on the GitHub
+// releases of the plugin. The checksums of the archives detect corrupted
+// downloads, they are not signed.
+package release
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultURL is the GitHub API endpoint of the latest release
+	DefaultURL = "https://api.github.com/repos/postfinance/kubectl-ns/releases/latest"
+	// URLEnv overrides the release endpoint, e.g. for a mirror
+	URLEnv = "KUBECTL_NS_RELEASE_URL"
+	// ChecksumsName is the asset listing the SHA-256 checksums of the
+	// archives
+	ChecksumsName = "checksums.txt"
+	// BinaryName is the name of the plugin binary in the archives
+	BinaryName = "kubectl-ns"
+)
+
+// Release is a published release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Client fetches releases and their assets
+type Client struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewDefaultClient returns a client for the releases of the plugin, the
+// endpoint can be overridden by the environment variable KUBECTL_NS_RELEASE_URL
+func NewDefaultClient() *Client {
+	url := os.Getenv(URLEnv)
+	if url == "" {
+		url = DefaultURL
+	}
+	return &Client{URL: url, Client: http.DefaultClient}
+}
+
+// Latest returns the latest release
+func (c *Client) Latest(ctx context.Context) (*Release, error) {
+	data, err := c.get(ctx, c.URL, "application/vnd.github.v3+json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest release: %w", err)
+	}
+	r := &Release{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse the latest release: %w", err)
+	}
+	if r.TagName == "" {
+		return nil, fmt.Errorf("the latest release has no tag")
+	}
+	return r, nil
+}
+
+// Asset returns the asset with the name
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// ArchiveName returns the name of the release archive for a platform like
+// it is built by goreleaser
+func ArchiveName(goos, goarch string) string {
+	if goarch == "amd64" {
+		goarch = "x86_64"
+	}
+	return fmt.Sprintf("%s_%s_%s.zip", BinaryName, goos, goarch)
+}
+
+// Binary downloads the archive of the platform, compares its checksum with
+// the checksums of the release and returns the binary it contains
+func (c *Client) Binary(ctx context.Context, r *Release, goos, goarch string) ([]byte, error) {
+	name := ArchiveName(goos, goarch)
+	archive, ok := r.Asset(name)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no archive %s", r.TagName, name)
+	}
+	checksums, ok := r.Asset(ChecksumsName)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s, the archive can't be checked", r.TagName, ChecksumsName)
+	}
+
+	sums, err := c.get(ctx, checksums.URL, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", ChecksumsName, err)
+	}
+	want, err := Checksum(sums, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.get(ctx, archive.URL, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch of %s: got %s, want %s", name, got, want)
+	}
+
+	binary := BinaryName
+	if goos == "windows" {
+		binary += ".exe"
+	}
+	return extract(data, binary)
+}
+
+// Checksum returns the SHA-256 checksum of the file name listed in the
+// output of sha256sum
+func Checksum(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum of %s found", name)
+}
+
+// extract returns the content of the file name in the zip archive
+func extract(archive []byte, name string) ([]byte, error) {
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the archive: %w", err)
+	}
+	for _, f := range r.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("archive contains no %s", name)
+}
+
+func (c *Client) get(ctx context.Context, url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Newer reports whether version a is newer than version b. Versions are
+// compared by their major, minor and patch numbers, a version which can't
+// be parsed like a development build is older than every release.
+func Newer(a, b string) bool {
+	va, okA := parse(a)
+	vb, okB := parse(b)
+	switch {
+	case !okA:
+		return false
+	case !okB:
+		return true
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			return va[i] > vb[i]
+		}
+	}
+	return false
+}
+
+// parse returns the major, minor and patch number of a version like v1.2.3,
+// pre-release and build suffixes are ignored
+func parse(version string) ([3]int, bool) {
+	var v [3]int
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package safefile
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown gives the file at path the owner of info. Changing the owner
+// requires privileges, without them the file keeps the owner of the process.
+func chown(path string, info os.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if int(stat.Uid) == os.Getuid() && int(stat.Gid) == os.Getgid() {
+		return nil
+	}
+	if err := os.Chown(path, int(stat.Uid), int(stat.Gid)); err != nil && !os.IsPermission(err) {
+		return err
+	}
+	return nil
+}
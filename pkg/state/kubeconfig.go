@@ -0,0 +1,102 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// ExtensionName is the key of the plugin state in the extensions of a
+// kubeconfig context
+const ExtensionName = "kubectl-ns"
+
+// ContextState is the part of the state of a context which travels with the
+// kubeconfig, it is stored in the extensions of the context
+type ContextState struct {
+	Previous  string   `json:"previous,omitempty"`
+	Recent    []string `json:"recent,omitempty"`
+	Favorites []string `json:"favorites,omitempty"`
+}
+
+// FromContext reads the state stored in the extensions of ctx, false is
+// returned if there is none
+func FromContext(ctx *api.Context) (*ContextState, bool, error) {
+	obj, ok := ctx.Extensions[ExtensionName]
+	if !ok || obj == nil {
+		return nil, false, nil
+	}
+
+	var data []byte
+	if unknown, ok := obj.(*runtime.Unknown); ok {
+		data = unknown.Raw
+	} else {
+		var err error
+		if data, err = json.Marshal(obj); err != nil {
+			return nil, false, err
+		}
+	}
+
+	c := &ContextState{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, false, fmt.Errorf("invalid %s extension: %w", ExtensionName, err)
+	}
+	return c, true, nil
+}
+
+// ToContext stores the state in the extensions of ctx, false is returned if
+// the stored state did not change
+func (c *ContextState) ToContext(ctx *api.Context) (bool, error) {
+	existing, ok, _ := FromContext(ctx)
+	if ok && reflect.DeepEqual(existing, c) || !ok && c.isEmpty() {
+		return false, nil
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return false, err
+	}
+	if ctx.Extensions == nil {
+		ctx.Extensions = map[string]runtime.Object{}
+	}
+	ctx.Extensions[ExtensionName] = &runtime.Unknown{Raw: data, ContentType: runtime.ContentTypeJSON}
+	return true, nil
+}
+
+func (c *ContextState) isEmpty() bool {
+	return c.Previous == "" && len(c.Recent) == 0 && len(c.Favorites) == 0
+}
+
+// ContextState returns the part of the state of context which travels with
+// the kubeconfig. Favorites of all contexts are kept locally.
+func (s *State) ContextState(context string) *ContextState {
+	c := &ContextState{
+		Previous: s.Previous[context],
+		Recent:   s.Recent[context],
+	}
+	for _, f := range s.Favorites {
+		if f.Context == context {
+			c.Favorites = append(c.Favorites, f.Namespace)
+		}
+	}
+	return c
+}
+
+// Merge adds the state of context stored in the kubeconfig, its previous
+// namespace and recent namespaces take precedence over the local ones
+func (s *State) Merge(context string, c *ContextState) {
+	if c.Previous != "" {
+		s.SetPrevious(context, c.Previous)
+	}
+	if len(c.Recent) > 0 {
+		if s.Recent == nil {
+			s.Recent = map[string][]string{}
+		}
+		s.Recent[context] = c.Recent
+	}
+	for _, ns := range c.Favorites {
+		s.AddFavorite(Favorite{Namespace: ns, Context: context})
+	}
+}
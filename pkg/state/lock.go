@@ -0,0 +1,73 @@
+package state
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout is the time to wait for another process to release the
+	// lock of the state file
+	lockTimeout = 5 * time.Second
+	// staleLock is the age after which a lock is considered to be left by
+	// a crashed process, the state is never locked for that long
+	staleLock = time.Minute
+)
+
+// Lock acquires the lock of the state file at path by creating path.lock,
+// the way kubectl locks the kubeconfig. The returned function releases it.
+// Every process changing the state has to hold the lock between loading
+// and saving it, otherwise the changes of concurrent processes get lost.
+func Lock(path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+
+	lock := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("state is locked by another process, remove %s if none is running", lock)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// UpdateDefault applies fn to the state at DefaultPath while holding its
+// lock, the state is only saved if fn reports a change
+func UpdateDefault(fn func(*State) (bool, error)) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := Load(path)
+	if err != nil {
+		return err
+	}
+	changed, err := fn(s)
+	if err != nil || !changed {
+		return err
+	}
+	return s.Save(path)
+}
@@ -0,0 +1,288 @@
+// Package state persists information kubectl-ns has to remember between
+// invocations, for example the previously active namespace of a context.
+package state
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const fileName = "state.json"
+
+// MaxRecent is the number of recently used namespaces kept per context
+const MaxRecent = 10
+
+// State is the persisted plugin state
+type State struct {
+	// Previous maps a context name to the namespace which was active
+	// before the last switch
+	Previous map[string]string `json:"previous,omitempty"`
+	// Favorites are the bookmarked namespaces
+	Favorites []Favorite `json:"favorites,omitempty"`
+	// Listings maps a context name to the namespaces of the last printed
+	// namespace list in their printed order
+	Listings map[string][]string `json:"listings,omitempty"`
+	// Reverts are the pending reverts of temporary namespace switches
+	Reverts []Revert `json:"reverts,omitempty"`
+	// Recent maps a context name to its most recently used namespaces,
+	// the last used first
+	Recent map[string][]string `json:"recent,omitempty"`
+	// Allowed maps the path of a trusted project file to the SHA-256 of
+	// its content when it was allowed
+	Allowed map[string]string `json:"allowed,omitempty"`
+}
+
+// Revert restores the previous namespace of a context at the given time,
+// unless the namespace was changed again in the meantime. Kubeconfig are
+// the files the context was read from, empty for the default files.
+type Revert struct {
+	Context    string    `json:"context"`
+	Namespace  string    `json:"namespace"`
+	Previous   string    `json:"previous"`
+	At         time.Time `json:"at"`
+	Kubeconfig []string  `json:"kubeconfig,omitempty"`
+}
+
+// Favorite is a bookmarked namespace, a favorite without context applies
+// to all contexts
+type Favorite struct {
+	Namespace string `json:"namespace"`
+	Context   string `json:"context,omitempty"`
+}
+
+// Dir returns the directory used to store the plugin state. It honours
+// KUBECTL_NS_STATE_DIR and XDG_STATE_HOME and falls back to
+// ~/.local/state/kubectl-ns.
+func Dir() (string, error) {
+	if dir := os.Getenv("KUBECTL_NS_STATE_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "kubectl-ns"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "kubectl-ns"), nil
+}
+
+// DefaultPath returns the path of the state file inside Dir
+func DefaultPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the state from path, a missing file results in an empty state
+func Load(path string) (*State, error) {
+	s := &State{}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadDefault reads the state from DefaultPath
+func LoadDefault() (*State, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// SaveDefault writes the state to DefaultPath
+func (s *State) SaveDefault() error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	return s.Save(path)
+}
+
+// Save writes the state to path, missing directories are created
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// SetPrevious remembers namespace as the previous namespace of context
+func (s *State) SetPrevious(context, namespace string) {
+	if s.Previous == nil {
+		s.Previous = map[string]string{}
+	}
+	s.Previous[context] = namespace
+}
+
+// AddRecent moves namespace to the front of the recently used namespaces of
+// context, at most MaxRecent namespaces are kept
+func (s *State) AddRecent(context, namespace string) {
+	if s.Recent == nil {
+		s.Recent = map[string][]string{}
+	}
+	recent := []string{namespace}
+	for _, ns := range s.Recent[context] {
+		if ns != namespace && len(recent) < MaxRecent {
+			recent = append(recent, ns)
+		}
+	}
+	s.Recent[context] = recent
+}
+
+// SetListing remembers the namespaces of the last listing of context, false
+// is returned if the listing did not change
+func (s *State) SetListing(context string, namespaces []string) bool {
+	if s.Listings == nil {
+		s.Listings = map[string][]string{}
+	}
+	if equal(s.Listings[context], namespaces) {
+		return false
+	}
+	s.Listings[context] = namespaces
+	return true
+}
+
+// ListingEntry returns the namespace at the 1-based index of the last
+// listing of context
+func (s *State) ListingEntry(context string, index int) (string, bool) {
+	listing := s.Listings[context]
+	if index < 1 || index > len(listing) {
+		return "", false
+	}
+	return listing[index-1], true
+}
+
+// AddFavorite bookmarks a namespace, false is returned if it is already a
+// favorite
+func (s *State) AddFavorite(f Favorite) bool {
+	for _, existing := range s.Favorites {
+		if existing == f {
+			return false
+		}
+	}
+	s.Favorites = append(s.Favorites, f)
+	return true
+}
+
+// RemoveFavorite removes a bookmark, false is returned if it did not exist
+func (s *State) RemoveFavorite(f Favorite) bool {
+	for i, existing := range s.Favorites {
+		if existing == f {
+			s.Favorites = append(s.Favorites[:i], s.Favorites[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsFavorite reports whether namespace is bookmarked for context or for all
+// contexts
+func (s *State) IsFavorite(context, namespace string) bool {
+	for _, f := range s.Favorites {
+		if f.Namespace == namespace && (f.Context == "" || f.Context == context) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowProject trusts the project file at path with the content of the
+// given SHA-256, false is returned if it was already trusted
+func (s *State) AllowProject(path, sum string) bool {
+	if s.Allowed == nil {
+		s.Allowed = map[string]string{}
+	}
+	if s.Allowed[path] == sum {
+		return false
+	}
+	s.Allowed[path] = sum
+	return true
+}
+
+// IsAllowedProject reports whether the project file at path is trusted and
+// did not change since
+func (s *State) IsAllowedProject(path, sum string) bool {
+	return s.Allowed[path] == sum
+}
+
+// AddRevert schedules a revert, it replaces a pending revert of the same
+// context in the same kubeconfig files
+func (s *State) AddRevert(r Revert) {
+	for i, existing := range s.Reverts {
+		if existing.Context == r.Context && equal(existing.Kubeconfig, r.Kubeconfig) {
+			s.Reverts[i] = r
+			return
+		}
+	}
+	s.Reverts = append(s.Reverts, r)
+}
+
+// DueReverts returns all reverts which are due at now, they stay pending
+// until they are removed with RemoveRevert
+func (s *State) DueReverts(now time.Time) []Revert {
+	due := []Revert{}
+	for _, r := range s.Reverts {
+		if !r.At.After(now) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// RemoveRevert removes the applied revert r, false is returned if it is not
+// pending anymore or was replaced by a later switch in the meantime
+func (s *State) RemoveRevert(r Revert) bool {
+	for i, existing := range s.Reverts {
+		if existing.Context == r.Context && equal(existing.Kubeconfig, r.Kubeconfig) && existing.At.Equal(r.At) {
+			s.Reverts = append(s.Reverts[:i], s.Reverts[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// NextRevert returns the time of the next pending revert, false is
+// returned if no revert is pending
+func (s *State) NextRevert() (time.Time, bool) {
+	next := time.Time{}
+	for _, r := range s.Reverts {
+		if next.IsZero() || r.At.Before(next) {
+			next = r.At
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}